@@ -2,9 +2,14 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"text/tabwriter"
+	"time"
 
 	"github.com/FramnkRulez/go-arangodb-migrator/pkg/migrator"
 	"github.com/arangodb/go-driver/v2/arangodb"
@@ -13,6 +18,14 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// StatusCommand holds the options of the status subcommand.
+type StatusCommand struct {
+	Format string `long:"format" description:"Output format (default: table)" choice:"table" choice:"json" default:"table"`
+}
+
+// ValidateCommand holds the options of the validate subcommand.
+type ValidateCommand struct{}
+
 type Options struct {
 	// Connection options
 	ArangoAddress  string `long:"arango-address" description:"Address for ArangoDB (default: http://localhost:8529)" env:"ARANGO_ADDRESS" default:"http://localhost:8529"`
@@ -30,6 +43,16 @@ type Options struct {
 	DryRun       bool `long:"dry-run" description:"Show what would be migrated without actually running migrations" env:"DRY_RUN"`
 	Force        bool `long:"force" description:"Force migration even if files have been modified" env:"FORCE"`
 	AutoRollback bool `long:"auto-rollback" description:"Enable automatic rollback of all migrations in batch if any migration fails" env:"AUTO_ROLLBACK"`
+	CommitEach   bool `long:"commit-each" description:"Record each migration as applied as soon as it succeeds, so a failed run can be resumed" env:"COMMIT_EACH"`
+
+	// Target options
+	Target string `long:"target" description:"Stop after the migration with this number, e.g. 000003 (default: apply all)" env:"TARGET"`
+	Steps  int    `long:"steps" description:"Apply only the next N pending migrations (default: all)" env:"STEPS"`
+
+	// Locking options
+	LockCollection string        `long:"lock-collection" description:"Collection holding the migration lock (default: <migration-collection>_lock)" env:"LOCK_COLLECTION"`
+	LockTimeout    time.Duration `long:"lock-timeout" description:"How long to wait for another migrator to release the lock (default: 5m)" env:"LOCK_TIMEOUT" default:"5m"`
+	LockTTL        time.Duration `long:"lock-ttl" description:"How long the lock stays valid without a heartbeat (default: 30s)" env:"LOCK_TTL" default:"30s"`
 
 	// Output options
 	Verbose bool `long:"verbose" short:"v" description:"Enable verbose logging" env:"VERBOSE"`
@@ -37,13 +60,20 @@ type Options struct {
 
 	// Version
 	Version bool `long:"version" description:"Show version information"`
+
+	// Commands
+	Status   StatusCommand   `command:"status" description:"Show applied and pending migrations"`
+	Validate ValidateCommand `command:"validate" description:"Check migration files for errors without connecting to ArangoDB"`
 }
 
-func parseArguments() Options {
+// parseArguments parses the command line and returns the options along with the
+// name of the selected subcommand, or an empty string when migrations should be run.
+func parseArguments() (Options, string) {
 	var opts Options
 	parser := flags.NewParser(&opts, flags.Default)
 	parser.Name = "arangodb-migrator"
 	parser.Usage = "[OPTIONS]"
+	parser.SubcommandsOptional = true
 
 	if _, err := parser.Parse(); err != nil {
 		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
@@ -69,12 +99,23 @@ func parseArguments() Options {
 		logrus.Infof("Dry Run: %t", opts.DryRun)
 		logrus.Infof("Force: %t", opts.Force)
 		logrus.Infof("Auto Rollback: %t", opts.AutoRollback)
+		logrus.Infof("Commit Each: %t", opts.CommitEach)
+		logrus.Infof("Target: %s", opts.Target)
+		logrus.Infof("Steps: %d", opts.Steps)
+		logrus.Infof("Lock Collection: %s", opts.LockCollection)
+		logrus.Infof("Lock Timeout: %s", opts.LockTimeout)
+		logrus.Infof("Lock TTL: %s", opts.LockTTL)
 		logrus.Infof("Verbose: %t", opts.Verbose)
 		logrus.Infof("Quiet: %t", opts.Quiet)
 		logrus.Info("=== End Configuration ===")
 	}
 
-	return opts
+	command := ""
+	if parser.Active != nil {
+		command = parser.Active.Name
+	}
+
+	return opts, command
 }
 
 func setupLogging(opts Options) {
@@ -93,7 +134,7 @@ func main() {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
 
-	opts := parseArguments()
+	opts, command := parseArguments()
 
 	// Handle version flag
 	if opts.Version {
@@ -119,11 +160,35 @@ func main() {
 		logrus.Infof("DRY_RUN: %s", os.Getenv("DRY_RUN"))
 		logrus.Infof("FORCE: %s", os.Getenv("FORCE"))
 		logrus.Infof("AUTO_ROLLBACK: %s", os.Getenv("AUTO_ROLLBACK"))
+		logrus.Infof("COMMIT_EACH: %s", os.Getenv("COMMIT_EACH"))
+		logrus.Infof("TARGET: %s", os.Getenv("TARGET"))
+		logrus.Infof("STEPS: %s", os.Getenv("STEPS"))
+		logrus.Infof("LOCK_COLLECTION: %s", os.Getenv("LOCK_COLLECTION"))
+		logrus.Infof("LOCK_TIMEOUT: %s", os.Getenv("LOCK_TIMEOUT"))
+		logrus.Infof("LOCK_TTL: %s", os.Getenv("LOCK_TTL"))
 		logrus.Infof("VERBOSE: %s", os.Getenv("VERBOSE"))
 		logrus.Infof("QUIET: %s", os.Getenv("QUIET"))
 		logrus.Info("=== End Environment Variables ===")
 	}
 
+	// Validation only reads the migration folder, so it doesn't need a connection
+	if command == "validate" {
+		err := migrator.ValidateFolder(opts.MigrationFolder)
+		var validationErrors migrator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			for _, validationError := range validationErrors {
+				logrus.Error(validationError)
+			}
+			logrus.Fatalf("Found %d problems in migration folder %s", len(validationErrors), opts.MigrationFolder)
+		}
+		if err != nil {
+			logrus.Fatalf("Failed to validate migrations: %v", err)
+		}
+
+		logrus.Infof("All migrations in %s are valid", opts.MigrationFolder)
+		return
+	}
+
 	// Validate required fields (unless showing version)
 	if !opts.Version {
 		if opts.Database == "" {
@@ -139,14 +204,27 @@ func main() {
 		}
 	}
 
-	logrus.Info("Starting ArangoDB migration...")
-
 	ctx := context.Background()
 	arangoClient, err := ConnectArango(ctx, opts.ArangoAddress, opts.ArangoUser, opts.ArangoPassword, true)
 	if err != nil {
 		logrus.Fatalf("Failed to connect to ArangoDB: %v", err)
 	}
 
+	if command == "status" {
+		statuses, err := DatabaseStatus(ctx, arangoClient, opts)
+		if err != nil {
+			logrus.Fatalf("Failed to get migration status: %v", err)
+		}
+
+		err = printStatus(os.Stdout, statuses, opts.Status.Format)
+		if err != nil {
+			logrus.Fatalf("Failed to print migration status: %v", err)
+		}
+		return
+	}
+
+	logrus.Info("Starting ArangoDB migration...")
+
 	_, err = MigrateDatabase(ctx, arangoClient, opts)
 	if err != nil {
 		logrus.Fatalf("Failed to migrate database: %v", err)
@@ -226,20 +304,19 @@ func MigrateDatabase(ctx context.Context, client arangodb.Client, opts Options)
 	logrus.Infof("Using migration folder: %s", migrationFolder)
 	logrus.Infof("Using migration collection: %s", opts.MigrationCollection)
 
-	if opts.DryRun {
-		logrus.Info("[DRY RUN] Would run migrations with the following options:")
-		logrus.Infof("  - Migration folder: %s", migrationFolder)
-		logrus.Infof("  - Migration collection: %s", opts.MigrationCollection)
-		logrus.Infof("  - Force mode: %t", opts.Force)
-		logrus.Infof("  - Auto rollback: %t", opts.AutoRollback)
-		return nil, nil
-	}
-
 	migrationOpts := migrator.MigrationOptions{
 		MigrationCollection: opts.MigrationCollection,
 		MigrationFolder:     migrationFolder,
 		Force:               opts.Force,
 		AutoRollback:        opts.AutoRollback,
+		CommitEachMigration: opts.CommitEach,
+		TargetVersion:       opts.Target,
+		Steps:               opts.Steps,
+		LockCollection:      opts.LockCollection,
+		LockWaitTimeout:     opts.LockTimeout,
+		LockTTL:             opts.LockTTL,
+		DryRun:              opts.DryRun,
+		Client:              client,
 	}
 
 	err = migrator.MigrateArangoDatabase(ctx, db, migrationOpts)
@@ -249,3 +326,62 @@ func MigrateDatabase(ctx context.Context, client arangodb.Client, opts Options)
 
 	return db, nil
 }
+
+func DatabaseStatus(ctx context.Context, client arangodb.Client, opts Options) ([]migrator.MigrationStatus, error) {
+	db, err := client.GetDatabase(ctx, opts.Database, &arangodb.GetDatabaseOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %v", err)
+	}
+
+	migrationFolder, err := filepath.Abs(opts.MigrationFolder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve migration folder path: %v", err)
+	}
+
+	statuses, err := migrator.Status(ctx, db, migrator.MigrationOptions{
+		MigrationCollection: opts.MigrationCollection,
+		MigrationFolder:     migrationFolder,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration status: %v", err)
+	}
+
+	return statuses, nil
+}
+
+func printStatus(w io.Writer, statuses []migrator.MigrationStatus, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if statuses == nil {
+			statuses = []migrator.MigrationStatus{}
+		}
+		return encoder.Encode(statuses)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MIGRATION\tSTATE\tAPPLIED AT\tSHA256\tDESCRIPTION")
+	for _, status := range statuses {
+		appliedAt := "-"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+
+		hash := status.AppliedSha256
+		if hash == "" {
+			hash = status.FileSha256
+		}
+		if len(hash) > 12 {
+			hash = hash[:12]
+		}
+
+		description := status.Description
+		if status.Error != "" {
+			description = status.Error
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", status.MigrationNumber, status.State, appliedAt, hash, description)
+	}
+
+	return tw.Flush()
+}
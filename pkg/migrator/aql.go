@@ -0,0 +1,141 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// executeAQLWithTracking runs the query of an executeAQL operation and records its
+// statistics.
+func executeAQLWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:    "executeAQL",
+		Name:    name,
+		Options: options,
+		Result:  make(map[string]interface{}),
+	}
+
+	query, ok := options["query"].(string)
+	if !ok || query == "" {
+		return result, fmt.Errorf("query option missing or not a string")
+	}
+
+	bindVars, _ := options["bindVars"].(map[string]interface{})
+	stats, err := executeAQL(ctx, db, query, bindVars, options)
+	if err != nil {
+		return result, err
+	}
+
+	result.Result["stats"] = stats
+	return result, nil
+}
+
+// rollbackExecuteAQL runs the rollbackQuery of an executeAQL operation.
+func rollbackExecuteAQL(ctx context.Context, db arangodb.Database, options map[string]interface{}) error {
+	rollbackQuery, ok := options["rollbackQuery"].(string)
+	if !ok {
+		return fmt.Errorf("cannot rollback executeAQL without a rollbackQuery")
+	}
+
+	_, err := executeAQL(ctx, db, rollbackQuery, rollbackBindVars(options), options)
+	return err
+}
+
+// rollbackBindVars returns the bind parameters of the rollback query, which default
+// to the bind parameters of the query.
+func rollbackBindVars(options map[string]interface{}) map[string]interface{} {
+	if bindVars, ok := options["rollbackBindVars"].(map[string]interface{}); ok {
+		return bindVars
+	}
+	bindVars, _ := options["bindVars"].(map[string]interface{})
+	return bindVars
+}
+
+// executeAQL runs a query with the batchSize and timeout options of an executeAQL
+// operation, reads all of its results and returns the query statistics.
+func executeAQL(ctx context.Context, db arangodb.Database, query string, bindVars map[string]interface{}, options map[string]interface{}) (map[string]interface{}, error) {
+	queryOptions := &arangodb.QueryOptions{
+		BindVars: bindVars,
+	}
+
+	if batchSize, ok := options["batchSize"].(float64); ok {
+		queryOptions.BatchSize = int(batchSize)
+	}
+
+	if timeout, ok := options["timeout"].(float64); ok {
+		// Let the server kill the query and stop waiting for it at the same time
+		queryOptions.Options.MaxRuntime = timeout
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout*float64(time.Second)))
+		defer cancel()
+	}
+
+	cursor, err := db.Query(ctx, query, queryOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer cursor.Close()
+
+	// Reading the results fetches the remaining batches, so the query runs to completion
+	var documents int64
+	for cursor.HasMore() {
+		var document interface{}
+		_, err := cursor.ReadDocument(ctx, &document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read query result: %w", err)
+		}
+		documents++
+	}
+
+	stats := cursor.Statistics()
+	return map[string]interface{}{
+		"documentsReturned": documents,
+		"writesExecuted":    stats.WritesExecutedInt,
+		"writesIgnored":     stats.WritesIgnoredInt,
+		"scannedFull":       stats.ScannedFullInt,
+		"scannedIndex":      stats.ScannedIndexInt,
+		"filtered":          stats.FilteredInt,
+		"executionTime":     stats.ExecutionTimeInt,
+	}, nil
+}
+
+// validateExecuteAQLOptions checks the options of an executeAQL operation.
+func validateExecuteAQLOptions(options map[string]interface{}) error {
+	if query, ok := options["query"].(string); !ok || query == "" {
+		return fmt.Errorf("query option missing or not a string")
+	}
+
+	if rollbackQuery, exists := options["rollbackQuery"]; exists {
+		if _, ok := rollbackQuery.(string); !ok {
+			return fmt.Errorf("rollbackQuery option not a string")
+		}
+	}
+
+	for _, key := range []string{"bindVars", "rollbackBindVars"} {
+		if value, exists := options[key]; exists {
+			if _, ok := value.(map[string]interface{}); !ok {
+				return fmt.Errorf("%s option not an object", key)
+			}
+		}
+	}
+
+	if value, exists := options["batchSize"]; exists {
+		batchSize, ok := value.(float64)
+		if !ok || batchSize < 1 || batchSize != math.Trunc(batchSize) {
+			return fmt.Errorf("batchSize option not a positive integer")
+		}
+	}
+
+	if value, exists := options["timeout"]; exists {
+		timeout, ok := value.(float64)
+		if !ok || timeout <= 0 {
+			return fmt.Errorf("timeout option not a positive number of seconds")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,477 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// builtinOperation implements one of the operation types of migration files. Unlike
+// an OperationHandler, its functions get the MigrationOptions, since some operations
+// need the client or the migration folder.
+type builtinOperation struct {
+	// apply applies the operation and returns the result needed to roll it back.
+	apply func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error)
+
+	// rollback undoes the operation. Operations that can't be undone return an error.
+	rollback func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error
+
+	// validate checks the options of the operation without a database. It is nil for
+	// operations without options.
+	validate func(operation Operation) error
+}
+
+// validateOptions runs the option checks of the operation, with missing options
+// treated as empty.
+func (b builtinOperation) validateOptions(operation Operation) error {
+	if b.validate == nil {
+		return nil
+	}
+	if operation.Options == nil {
+		operation.Options = map[string]interface{}{}
+	}
+	return b.validate(operation)
+}
+
+// builtinHandler is the OperationHandler a Migrator registers for a built-in
+// operation type.
+type builtinHandler struct {
+	operation builtinOperation
+	options   *MigrationOptions
+}
+
+func (h *builtinHandler) Apply(ctx context.Context, db arangodb.Database, operation Operation) (OperationResult, error) {
+	return h.operation.apply(ctx, db, *h.options, operation)
+}
+
+func (h *builtinHandler) Rollback(ctx context.Context, db arangodb.Database, operation OperationResult) error {
+	return h.operation.rollback(ctx, db, *h.options, operation)
+}
+
+func (h *builtinHandler) Validate(operation Operation) error {
+	if operation.Name == "" {
+		return fmt.Errorf("name missing")
+	}
+	return h.operation.validateOptions(operation)
+}
+
+// warnUnknownOptions logs the options of an operation that are ignored because the
+// operation doesn't know them.
+func warnUnknownOptions(options MigrationOptions, operation Operation, unknown []string) {
+	if len(unknown) > 0 {
+		options.logger().Warn("ignoring unknown options", "operation", operation.Type, "name", operation.Name, "options", unknown)
+	}
+}
+
+// cannotRollback returns the rollback function of an operation that can't be undone.
+func cannotRollback(message string) func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+	return func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+		return fmt.Errorf("cannot rollback %s", message)
+	}
+}
+
+// indexOperation returns the built-in operation creating an index with create. Every
+// index type is rolled back with rollbackCreateIndex.
+func indexOperation(create func(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (bool, error)) builtinOperation {
+	return builtinOperation{
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return createIndexWithTracking(ctx, db, operation.Type, operation.Name, operation.Options, create)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return rollbackCreateIndex(ctx, db, operation)
+		},
+		validate: func(operation Operation) error {
+			return validateIndexOptions(operation.Type, operation.Name, operation.Options)
+		},
+	}
+}
+
+// clientIndexOperation is indexOperation for index types that are created through
+// MigrationOptions.Client because the driver has no call for them.
+func clientIndexOperation(create func(ctx context.Context, db arangodb.Database, client arangodb.Client, name string, options map[string]interface{}) (bool, error)) builtinOperation {
+	return builtinOperation{
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return createIndexWithTracking(ctx, db, operation.Type, operation.Name, operation.Options, clientIndex(options.Client, create))
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return rollbackCreateIndex(ctx, db, operation)
+		},
+		validate: func(operation Operation) error {
+			return validateIndexOptions(operation.Type, operation.Name, operation.Options)
+		},
+	}
+}
+
+// viewOperation returns the built-in operation creating a view with create.
+func viewOperation(create func(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error) builtinOperation {
+	return builtinOperation{
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return createViewWithTracking(ctx, db, operation.Type, operation.Name, operation.Options, create)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return deleteView(ctx, db, operation.Name)
+		},
+		validate: func(operation Operation) error {
+			return validateViewOptions(operation.Type, operation.Options)
+		},
+	}
+}
+
+// accessOperation is the built-in operation of grantDatabaseAccess,
+// grantCollectionAccess and revokeAccess.
+var accessOperation = builtinOperation{
+	apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+		return changeAccessWithTracking(ctx, db, options.Client, operation.Type, operation.Name, operation.Options)
+	},
+	rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+		return rollbackChangeAccess(ctx, options.Client, operation)
+	},
+	validate: func(operation Operation) error {
+		return validateAccessOptions(operation.Type, operation.Options)
+	},
+}
+
+// builtinOperations are the operation types every Migrator starts with, keyed by
+// operation type.
+var builtinOperations = map[string]builtinOperation{
+	"createCollection": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			warnUnknownOptions(options, operation, unknownOptions(operation.Options, &arangodb.CreateCollectionProperties{}, "type"))
+			return createCollectionWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return deleteCollection(ctx, db, operation.Name)
+		},
+		validate: func(operation Operation) error {
+			_, err := collectionProperties(operation.Options)
+			return err
+		},
+	},
+	"modifyCollection": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return modifyCollectionWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return rollbackModifyCollection(ctx, db, options.logger(), operation)
+		},
+		validate: func(operation Operation) error {
+			_, err := modifyCollectionProperties(operation.Options)
+			return err
+		},
+	},
+	"setCollectionSchema": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return setCollectionSchemaWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return rollbackModifyCollection(ctx, db, options.logger(), operation)
+		},
+		validate: func(operation Operation) error {
+			_, err := collectionSchema(operation.Options)
+			return err
+		},
+	},
+	"renameCollection": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return renameCollectionWithTracking(ctx, db, options.Client, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			newName, _ := operation.Options["newName"].(string)
+			return renameCollection(ctx, db, options.Client, newName, operation.Name)
+		},
+		validate: func(operation Operation) error {
+			if newName, ok := operation.Options["newName"].(string); !ok || newName == "" {
+				return fmt.Errorf("newName option missing or not a string")
+			}
+			return nil
+		},
+	},
+	"truncateCollection": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return truncateCollectionWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return rollbackTruncateCollection(ctx, db, operation)
+		},
+		validate: func(operation Operation) error {
+			_, _, err := truncateBackup(operation.Name, operation.Options)
+			return err
+		},
+	},
+	"deleteCollection": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return deleteCollectionWithTracking(ctx, db, operation.Name)
+		},
+		rollback: cannotRollback("collection deletion"),
+	},
+
+	"createPersistentIndex": indexOperation(createPersistentIndex),
+	"createGeoIndex":        indexOperation(createGeoIndex),
+	"createTTLIndex":        indexOperation(createTTLIndex),
+	"createInvertedIndex":   indexOperation(createInvertedIndex),
+	"createMDIIndex":        indexOperation(createMDIIndex),
+	"createVectorIndex":     clientIndexOperation(createVectorIndex),
+	"createFulltextIndex":   clientIndexOperation(createFulltextIndex),
+	"deleteIndex": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return deleteIndexWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: cannotRollback("index deletion"),
+		validate: func(operation Operation) error {
+			if _, ok := operation.Options["collection"].(string); !ok {
+				return fmt.Errorf("collection name missing or not a string")
+			}
+			return nil
+		},
+	},
+
+	"createGraph": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			warnUnknownOptions(options, operation, unknownOptions(operation.Options, &arangodb.GraphDefinition{}, graphDefinitionOptions...))
+			return createGraphWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return deleteGraph(ctx, db, operation.Name)
+		},
+		validate: func(operation Operation) error {
+			_, _, err := graphDefinition(operation.Options)
+			return err
+		},
+	},
+	"addEdgeDefinition": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return addEdgeDefinitionWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return deleteEdgeDefinition(ctx, db, operation.Name, operation.Options)
+		},
+		validate: func(operation Operation) error {
+			_, err := parseEdgeDefinition(edgeDefinitionOptions(operation.Options))
+			return err
+		},
+	},
+	"replaceEdgeDefinition": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return replaceEdgeDefinitionWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return restoreEdgeDefinition(ctx, db, operation)
+		},
+		validate: func(operation Operation) error {
+			_, err := parseEdgeDefinition(edgeDefinitionOptions(operation.Options))
+			if err != nil {
+				return err
+			}
+			_, err = graphSatellites(operation.Options)
+			return err
+		},
+	},
+	"deleteEdgeDefinition": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return deleteEdgeDefinitionWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: cannotRollback("edge definition deletion"),
+		validate: func(operation Operation) error {
+			if _, ok := edgeDefinitionOptions(operation.Options)["collection"].(string); !ok {
+				return fmt.Errorf("collection option missing or not a string")
+			}
+			return nil
+		},
+	},
+	"addVertexCollection": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return addVertexCollectionWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return rollbackAddVertexCollection(ctx, db, operation)
+		},
+		validate: validateVertexCollectionOptions,
+	},
+	"removeVertexCollection": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return removeVertexCollectionWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			collection, _ := operation.Options["collection"].(string)
+			return addVertexCollection(ctx, db, operation.Name, collection, nil)
+		},
+		validate: validateVertexCollectionOptions,
+	},
+
+	"addDocument": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return addDocumentWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			// Use the tracked document ID for deletion
+			if docID, ok := operation.Result["documentID"].(string); ok {
+				return deleteDocumentByID(ctx, db, operation.Name, docID)
+			}
+			return deleteDocument(ctx, db, operation.Name, operation.Options)
+		},
+		validate: func(operation Operation) error {
+			if _, ok := operation.Options["document"].(map[string]interface{}); !ok {
+				return fmt.Errorf("document field missing or not an object")
+			}
+			return nil
+		},
+	},
+	"updateDocument": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return updateDocumentWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			// Restore the original document state
+			if originalDoc, ok := operation.RollbackData["originalDocument"].(map[string]interface{}); ok {
+				return replaceDocument(ctx, db, operation.Name, originalDoc)
+			}
+			return fmt.Errorf("cannot rollback document update - no original state available")
+		},
+		validate: validateDocumentKey,
+	},
+	"deleteDocument": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return deleteDocumentWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			// Restore the deleted document
+			if originalDoc, ok := operation.RollbackData["originalDocument"].(map[string]interface{}); ok {
+				return restoreDocument(ctx, db, operation.Name, originalDoc)
+			}
+			return fmt.Errorf("cannot rollback document deletion - no original state available")
+		},
+		validate: validateDocumentKey,
+	},
+	"importDocuments": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			fsys, dir := migrationFolder(options)
+			return importDocumentsWithTracking(ctx, db, options.logger(), fsys, dir, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return rollbackImportDocuments(ctx, db, operation)
+		},
+		validate: func(operation Operation) error {
+			return validateImportDocumentsOptions(operation.Options)
+		},
+	},
+
+	"executeAQL": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return executeAQLWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return rollbackExecuteAQL(ctx, db, operation.Options)
+		},
+		validate: func(operation Operation) error {
+			return validateExecuteAQLOptions(operation.Options)
+		},
+	},
+
+	"createDatabase": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return createDatabaseWithTracking(ctx, options.Client, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return deleteDatabase(ctx, options.Client, operation.Name)
+		},
+		validate: func(operation Operation) error {
+			_, err := databaseOptions(operation.Options)
+			return err
+		},
+	},
+	"createUser": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return createUserWithTracking(ctx, options.Client, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return removeUser(ctx, options.Client, operation.Name)
+		},
+		validate: func(operation Operation) error {
+			_, err := userOptions(operation.Options)
+			return err
+		},
+	},
+	"grantDatabaseAccess":   accessOperation,
+	"grantCollectionAccess": accessOperation,
+	"revokeAccess":          accessOperation,
+
+	"createAnalyzer": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return createAnalyzerWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return deleteAnalyzer(ctx, db, operation.Name)
+		},
+		validate: func(operation Operation) error {
+			_, err := analyzerDefinition(operation.Name, operation.Options)
+			return err
+		},
+	},
+	"deleteAnalyzer": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return deleteAnalyzerWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return restoreAnalyzer(ctx, db, operation)
+		},
+		validate: func(operation Operation) error {
+			if force, exists := operation.Options["force"]; exists {
+				if _, ok := force.(bool); !ok {
+					return fmt.Errorf("force option not a boolean")
+				}
+			}
+			return nil
+		},
+	},
+	"createArangoSearchView": viewOperation(createArangoSearchView),
+	"createSearchAliasView":  viewOperation(createSearchAliasView),
+	"updateViewProperties": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return updateViewPropertiesWithTracking(ctx, db, operation.Name, operation.Options)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return restoreViewProperties(ctx, db, operation)
+		},
+		validate: func(operation Operation) error {
+			if len(operation.Options) == 0 {
+				return fmt.Errorf("no view properties to update")
+			}
+			return validateViewProperties("", operation.Options)
+		},
+	},
+	"deleteView": {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return deleteViewWithTracking(ctx, db, operation.Name)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return restoreView(ctx, db, operation)
+		},
+	},
+
+	// Go migrations are recorded as a single operation, which can't be used in
+	// migration files
+	goMigrationOperationType: {
+		apply: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation Operation) (OperationResult, error) {
+			return runGoMigration(ctx, db, operation.Name)
+		},
+		rollback: func(ctx context.Context, db arangodb.Database, options MigrationOptions, operation OperationResult) error {
+			return rollbackGoMigration(ctx, db, operation.Name)
+		},
+	},
+}
+
+func validateVertexCollectionOptions(operation Operation) error {
+	if _, ok := operation.Options["collection"].(string); !ok {
+		return fmt.Errorf("collection option missing or not a string")
+	}
+	_, err := graphSatellites(operation.Options)
+	return err
+}
+
+func validateDocumentKey(operation Operation) error {
+	if _, ok := operation.Options["_key"].(string); !ok {
+		return fmt.Errorf("document key missing or not a string")
+	}
+	return nil
+}
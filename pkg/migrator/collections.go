@@ -0,0 +1,470 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/arangodb/go-driver/v2/arangodb/shared"
+	"github.com/arangodb/go-driver/v2/connection"
+)
+
+// collectionProperties converts the options of a createCollection operation to the
+// driver's collection properties. Besides the collection type, every property ArangoDB
+// accepts when creating a collection can be set:
+//
+//	{
+//		"type": "createCollection",
+//		"name": "orders",
+//		"options": {
+//		  "type": "document",
+//		  "numberOfShards": 3,
+//		  "replicationFactor": 2,
+//		  "writeConcern": 2,
+//		  "shardKeys": ["customerId"],
+//		  "keyOptions": {"type": "uuid"},
+//		  "waitForSync": true,
+//		  "cacheEnabled": true
+//		}
+//	}
+func collectionProperties(options map[string]interface{}) (*arangodb.CreateCollectionProperties, error) {
+	props := &arangodb.CreateCollectionProperties{}
+
+	collType, exists := options["type"]
+	if !exists {
+		return nil, fmt.Errorf("collection type not specified")
+	}
+
+	switch collType {
+	case "document":
+		props.Type = arangodb.CollectionTypeDocument
+	case "edge":
+		props.Type = arangodb.CollectionTypeEdge
+	default:
+		return nil, fmt.Errorf("unrecognized collection type: %v", collType)
+	}
+
+	// The type is a name in migration files but a number in the driver. Unknown
+	// options were always ignored, so they are only reported with a warning.
+	err := decodeKnownOptions(options, props, "type")
+	if err != nil {
+		return nil, err
+	}
+
+	return props, nil
+}
+
+// modifyCollectionProperties converts the options of a modifyCollection operation to
+// the driver's options for changing the mutable properties of a collection.
+func modifyCollectionProperties(options map[string]interface{}) (arangodb.SetCollectionPropertiesOptions, error) {
+	var props arangodb.SetCollectionPropertiesOptions
+
+	if len(options) == 0 {
+		return props, fmt.Errorf("no collection properties to modify")
+	}
+
+	err := decodeOptions(options, &props)
+	return props, err
+}
+
+//	{
+//		"type": "modifyCollection",
+//		"name": "orders",
+//		"options": {
+//		  "waitForSync": false,
+//		  "cacheEnabled": true
+//		}
+//	}
+func modifyCollectionWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:         "modifyCollection",
+		Name:         name,
+		Options:      options,
+		Result:       make(map[string]interface{}),
+		RollbackData: make(map[string]interface{}),
+	}
+
+	props, err := modifyCollectionProperties(options)
+	if err != nil {
+		return result, err
+	}
+
+	coll, err := db.GetCollection(ctx, name, &arangodb.GetCollectionOptions{})
+	if err != nil {
+		return result, fmt.Errorf("failed to get collection '%s' for modification: %w", name, err)
+	}
+
+	current, err := coll.Properties(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to read properties of collection '%s': %w", name, err)
+	}
+
+	// Store the previous value of every property that is changed for rollback
+	previous := make(map[string]interface{})
+	for key := range options {
+		switch key {
+		case "waitForSync":
+			previous[key] = current.WaitForSync
+		case "cacheEnabled":
+			previous[key] = current.CacheEnabled
+		case "replicationFactor":
+			previous[key] = current.ReplicationFactor
+		case "writeConcern":
+			previous[key] = current.WriteConcern
+		case "schema":
+			if current.Schema != nil {
+				previous[key] = current.Schema
+			} else {
+				// An empty schema removes the schema again
+				previous[key] = map[string]interface{}{}
+			}
+		case "computedValues":
+			previous[key] = current.ComputedValues
+		}
+	}
+	result.RollbackData["previousProperties"] = previous
+
+	err = coll.SetProperties(ctx, props)
+	if err != nil {
+		return result, fmt.Errorf("failed to modify collection '%s': %w", name, err)
+	}
+
+	return result, nil
+}
+
+// collectionSchema converts the options of a setCollectionSchema operation to a
+// collection schema. The level defaults to strict.
+//
+//	{
+//		"type": "setCollectionSchema",
+//		"name": "users",
+//		"options": {
+//		  "rule": {
+//		    "type": "object",
+//		    "properties": {"email": {"type": "string"}},
+//		    "required": ["email"]
+//		  },
+//		  "level": "moderate",
+//		  "message": "users need an email address"
+//		}
+//	}
+func collectionSchema(options map[string]interface{}) (map[string]interface{}, error) {
+	rule, ok := options["rule"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("rule option missing or not an object")
+	}
+
+	level := arangodb.CollectionSchemaLevelStrict
+	if value, exists := options["level"]; exists {
+		levelName, _ := value.(string)
+		switch arangodb.CollectionSchemaLevel(levelName) {
+		case arangodb.CollectionSchemaLevelNone, arangodb.CollectionSchemaLevelNew,
+			arangodb.CollectionSchemaLevelModerate, arangodb.CollectionSchemaLevelStrict:
+			level = arangodb.CollectionSchemaLevel(levelName)
+		default:
+			return nil, fmt.Errorf("level option must be one of none, new, moderate or strict")
+		}
+	}
+
+	schema := map[string]interface{}{
+		"rule":  rule,
+		"level": string(level),
+	}
+
+	if value, exists := options["message"]; exists {
+		message, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("message option not a string")
+		}
+		schema["message"] = message
+	}
+
+	return schema, nil
+}
+
+// setCollectionSchemaWithTracking sets the schema of a collection. It is a
+// modifyCollection of the schema property, so the previous schema is recorded and
+// restored in the same way.
+func setCollectionSchemaWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+	schema, err := collectionSchema(options)
+	if err != nil {
+		return OperationResult{Type: "setCollectionSchema", Name: name, Options: options}, err
+	}
+
+	result, err := modifyCollectionWithTracking(ctx, db, name, map[string]interface{}{"schema": schema})
+	result.Type = "setCollectionSchema"
+	result.Options = options
+	return result, err
+}
+
+// rollbackModifyCollection restores the properties recorded before a modifyCollection
+// operation.
+func rollbackModifyCollection(ctx context.Context, db arangodb.Database, log *slog.Logger, operation OperationResult) error {
+	previous, ok := operation.RollbackData["previousProperties"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("cannot rollback collection modification - no previous properties available")
+	}
+
+	var props arangodb.SetCollectionPropertiesOptions
+	err := decodeOptions(previous, &props)
+	if err != nil {
+		return fmt.Errorf("failed to decode previous properties: %w", err)
+	}
+
+	if _, changed := previous["computedValues"]; changed && len(props.ComputedValues) == 0 {
+		log.Warn("computed values added to collection can't be removed by rollback", "operation", operation.Type, "name", operation.Name)
+	}
+
+	coll, err := db.GetCollection(ctx, operation.Name, &arangodb.GetCollectionOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get collection '%s' for modification: %w", operation.Name, err)
+	}
+
+	err = coll.SetProperties(ctx, props)
+	if err != nil {
+		return fmt.Errorf("failed to restore properties of collection '%s': %w", operation.Name, err)
+	}
+
+	return nil
+}
+
+//	{
+//		"type": "renameCollection",
+//		"name": "users_v1",
+//		"options": {
+//		  "newName": "users"
+//		}
+//	}
+func renameCollectionWithTracking(ctx context.Context, db arangodb.Database, client arangodb.Client, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:    "renameCollection",
+		Name:    name,
+		Options: options,
+		Result:  make(map[string]interface{}),
+	}
+
+	newName, ok := options["newName"].(string)
+	if !ok || newName == "" {
+		return result, fmt.Errorf("newName option missing or not a string")
+	}
+
+	err := renameCollection(ctx, db, client, name, newName)
+	if err != nil {
+		return result, err
+	}
+
+	result.Result["newName"] = newName
+	return result, nil
+}
+
+// renameCollection renames a collection. The driver has no call for it, so the
+// request is sent over the client's connection. ArangoDB only supports renaming
+// collections on single servers.
+func renameCollection(ctx context.Context, db arangodb.Database, client arangodb.Client, name string, newName string) error {
+	if client == nil {
+		return fmt.Errorf("renaming collection '%s' requires MigrationOptions.Client", name)
+	}
+
+	var response struct {
+		shared.ResponseStruct `json:",inline"`
+	}
+
+	url := connection.NewUrl("_db", db.Name(), "_api", "collection", name, "rename")
+	resp, err := connection.CallPut(ctx, client.Connection(), url, &response, map[string]string{"name": newName})
+	if err != nil {
+		return fmt.Errorf("failed to rename collection '%s' to '%s': %w", name, newName, err)
+	}
+
+	if resp.Code() != http.StatusOK {
+		return fmt.Errorf("failed to rename collection '%s' to '%s': %w", name, newName, response.AsArangoErrorWithCode(resp.Code()))
+	}
+
+	return nil
+}
+
+// Backup modes of a truncateCollection operation
+const (
+	truncateBackupNone         = "none"
+	truncateBackupRollbackData = "rollbackData"
+	truncateBackupCollection   = "collection"
+)
+
+// truncateBackup returns the backup mode of a truncateCollection operation and, for
+// the collection mode, the name of the backup collection.
+func truncateBackup(name string, options map[string]interface{}) (string, string, error) {
+	backup := truncateBackupNone
+	if value, exists := options["backup"]; exists {
+		backup, _ = value.(string)
+		switch backup {
+		case truncateBackupNone, truncateBackupRollbackData, truncateBackupCollection:
+		default:
+			return "", "", fmt.Errorf("backup option must be one of none, rollbackData or collection")
+		}
+	}
+
+	backupCollection := name + "_backup"
+	if value, exists := options["backupCollection"]; exists {
+		if backup != truncateBackupCollection {
+			return "", "", fmt.Errorf("backupCollection option requires backup to be collection")
+		}
+		backupCollection, _ = value.(string)
+		if backupCollection == "" {
+			return "", "", fmt.Errorf("backupCollection option not a string")
+		}
+	}
+
+	if backup != truncateBackupCollection {
+		backupCollection = ""
+	}
+	return backup, backupCollection, nil
+}
+
+// truncateCollectionWithTracking removes all documents of a collection. Without a
+// backup the truncation can't be rolled back. The rollbackData backup stores the
+// documents in the applied migration record, which suits small collections such as
+// test seeds; the collection backup copies them to another collection that is left
+// in place after the migration.
+//
+//	{
+//		"type": "truncateCollection",
+//		"name": "sessions",
+//		"options": {
+//		  "backup": "collection",
+//		  "backupCollection": "sessions_backup"
+//		}
+//	}
+func truncateCollectionWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:         "truncateCollection",
+		Name:         name,
+		Options:      options,
+		Result:       make(map[string]interface{}),
+		RollbackData: make(map[string]interface{}),
+	}
+
+	backup, backupCollection, err := truncateBackup(name, options)
+	if err != nil {
+		return result, err
+	}
+
+	coll, err := db.GetCollection(ctx, name, &arangodb.GetCollectionOptions{})
+	if err != nil {
+		return result, fmt.Errorf("failed to get collection '%s' for truncation: %w", name, err)
+	}
+
+	switch backup {
+	case truncateBackupRollbackData:
+		documents, err := readAllDocuments(ctx, db, name)
+		if err != nil {
+			return result, err
+		}
+		result.RollbackData["documents"] = documents
+
+	case truncateBackupCollection:
+		props, err := coll.Properties(ctx)
+		if err != nil {
+			return result, fmt.Errorf("failed to read properties of collection '%s': %w", name, err)
+		}
+		_, err = db.CreateCollection(ctx, backupCollection, &arangodb.CreateCollectionProperties{Type: props.Type})
+		if err != nil {
+			return result, fmt.Errorf("failed to create backup collection '%s': %w", backupCollection, err)
+		}
+		result.RollbackData["backupCollection"] = backupCollection
+		err = copyDocuments(ctx, db, name, backupCollection)
+		if err != nil {
+			return result, dropTruncateBackup(ctx, db, result, err)
+		}
+	}
+
+	count, err := coll.Count(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to count documents of collection '%s': %w", name, err)
+		return result, dropTruncateBackup(ctx, db, result, err)
+	}
+
+	err = coll.Truncate(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to truncate collection '%s': %w", name, err)
+		return result, dropTruncateBackup(ctx, db, result, err)
+	}
+
+	result.Result["documentsRemoved"] = count
+	return result, nil
+}
+
+// dropTruncateBackup removes the backup collection created by a truncation that
+// failed with err, since the failed operation is not recorded or rolled back. It
+// returns err, joined with the error of removing the backup.
+func dropTruncateBackup(ctx context.Context, db arangodb.Database, operation OperationResult, err error) error {
+	backupCollection, ok := operation.RollbackData["backupCollection"].(string)
+	if !ok {
+		return err
+	}
+
+	dropErr := deleteCollection(ctx, db, backupCollection)
+	if dropErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to remove backup collection '%s': %w", backupCollection, dropErr))
+	}
+	return err
+}
+
+// rollbackTruncateCollection restores the documents of a truncated collection from
+// its backup.
+func rollbackTruncateCollection(ctx context.Context, db arangodb.Database, operation OperationResult) error {
+	if backupCollection, ok := operation.RollbackData["backupCollection"].(string); ok {
+		err := copyDocuments(ctx, db, backupCollection, operation.Name)
+		if err != nil {
+			return err
+		}
+		return deleteCollection(ctx, db, backupCollection)
+	}
+
+	documents, ok := operation.RollbackData["documents"].([]interface{})
+	if !ok {
+		return fmt.Errorf("cannot rollback collection truncation without a backup")
+	}
+
+	_, err := executeAQL(ctx, db, "FOR d IN @documents INSERT d INTO @@collection OPTIONS { overwriteMode: 'replace' }",
+		map[string]interface{}{"documents": documents, "@collection": operation.Name}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to restore documents of collection '%s': %w", operation.Name, err)
+	}
+
+	return nil
+}
+
+// readAllDocuments returns all documents of a collection.
+func readAllDocuments(ctx context.Context, db arangodb.Database, name string) ([]interface{}, error) {
+	cursor, err := db.Query(ctx, "FOR d IN @@collection RETURN d", &arangodb.QueryOptions{
+		BindVars: map[string]interface{}{"@collection": name},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read documents of collection '%s': %w", name, err)
+	}
+	defer cursor.Close()
+
+	documents := []interface{}{}
+	for cursor.HasMore() {
+		var document map[string]interface{}
+		_, err := cursor.ReadDocument(ctx, &document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read documents of collection '%s': %w", name, err)
+		}
+		documents = append(documents, document)
+	}
+
+	return documents, nil
+}
+
+// copyDocuments copies all documents of a collection to another one, keeping their keys.
+func copyDocuments(ctx context.Context, db arangodb.Database, from string, to string) error {
+	_, err := executeAQL(ctx, db, "FOR d IN @@from INSERT d INTO @@to OPTIONS { overwriteMode: 'replace' }",
+		map[string]interface{}{"@from": from, "@to": to}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to copy documents from '%s' to '%s': %w", from, to, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package migrator
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrChecksumMismatch is returned when a migration file has been modified since
+	// it was applied and MigrationOptions.Force is not set.
+	ErrChecksumMismatch = errors.New("migration file has been modified since last applied")
+
+	// ErrUnsupportedOperation is returned for operations of a type that has no
+	// built-in or registered handler.
+	ErrUnsupportedOperation = errors.New("unsupported operation type")
+
+	// ErrRollbackFailed is returned when applied operations could not be rolled back,
+	// so the database may be in an inconsistent state.
+	ErrRollbackFailed = errors.New("rollback failed")
+)
+
+// MigrationError is returned when an operation of a migration fails. It wraps the
+// error of the operation, which may in turn wrap the error returned by ArangoDB:
+//
+//	var migrationErr *migrator.MigrationError
+//	if errors.As(err, &migrationErr) {
+//		log.Printf("operation %d (%s) of migration %s failed", migrationErr.OperationIndex, migrationErr.OperationType, migrationErr.Number)
+//	}
+//	if shared.IsConflict(err) {
+//		// retry
+//	}
+type MigrationError struct {
+	// Number is the full migration number, like "000001_create_users".
+	Number string
+
+	// Direction is DirectionDown if an operation of the 'down' list failed.
+	Direction Direction
+
+	// OperationIndex is the position of the failed operation in the 'up' or 'down'
+	// list, or -1 if the migration failed before or after its operations.
+	OperationIndex int
+
+	// OperationType and Name are the type and resource name of the failed operation.
+	OperationType string
+	Name          string
+
+	Err error
+}
+
+func (e *MigrationError) Error() string {
+	if e.Direction == DirectionDown {
+		return fmt.Sprintf("rollback operation failed for migration %s: %v", e.Number, e.Err)
+	}
+	return fmt.Sprintf("migration operation failed for migration %s: %v", e.Number, e.Err)
+}
+
+func (e *MigrationError) Unwrap() error {
+	return e.Err
+}
+
+// operationError returns a MigrationError for an operation of a migration.
+func operationError(migrationNumber string, direction Direction, index int, operation Operation, err error) *MigrationError {
+	return &MigrationError{
+		Number:         migrationNumber,
+		Direction:      direction,
+		OperationIndex: index,
+		OperationType:  operation.Type,
+		Name:           operation.Name,
+		Err:            err,
+	}
+}
+
+// migrationError returns err as a MigrationError of the migration, unless it already
+// wraps one for a failed operation.
+func migrationError(migrationNumber string, direction Direction, err error) error {
+	var migrationErr *MigrationError
+	if errors.As(err, &migrationErr) {
+		return err
+	}
+	return &MigrationError{Number: migrationNumber, Direction: direction, OperationIndex: -1, Err: err}
+}
+
+// rollbackError marks an error as ErrRollbackFailed without changing its message.
+type rollbackError struct {
+	err error
+}
+
+func (e *rollbackError) Error() string {
+	return e.err.Error()
+}
+
+func (e *rollbackError) Unwrap() []error {
+	return []error{ErrRollbackFailed, e.err}
+}
@@ -0,0 +1,74 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrationError tests the typed errors returned for failed migrations
+func TestMigrationError(t *testing.T) {
+	cause := errors.New("collection not found")
+	operation := Operation{Type: "createPersistentIndex", Name: "idx_users_email"}
+
+	err := fmt.Errorf("batch failed: %w", operationError("000002_indexes", DirectionUp, 1, operation, cause))
+	assert.EqualError(t, err, "batch failed: migration operation failed for migration 000002_indexes: collection not found")
+	assert.ErrorIs(t, err, cause)
+
+	var migrationErr *MigrationError
+	require.ErrorAs(t, err, &migrationErr)
+	assert.Equal(t, &MigrationError{
+		Number:         "000002_indexes",
+		Direction:      DirectionUp,
+		OperationIndex: 1,
+		OperationType:  "createPersistentIndex",
+		Name:           "idx_users_email",
+		Err:            cause,
+	}, migrationErr)
+
+	// An error of a failed operation is kept, others are wrapped
+	assert.Same(t, err, migrationError("000002_indexes", DirectionUp, err))
+	err = migrationError("000002_indexes", DirectionDown, cause)
+	assert.EqualError(t, err, "rollback operation failed for migration 000002_indexes: collection not found")
+	require.ErrorAs(t, err, &migrationErr)
+	assert.Equal(t, -1, migrationErr.OperationIndex)
+
+	ctx := context.Background()
+	m := New(nil)
+
+	_, err = m.applyOperation(ctx, Operation{Type: "setSetting", Name: "theme"})
+	assert.ErrorIs(t, err, ErrUnsupportedOperation)
+	assert.ErrorIs(t, validateOperation(Operation{Type: "setSetting", Name: "theme"}), ErrUnsupportedOperation)
+
+	err = m.autoRollback(ctx, []OperationResult{{Type: "setSetting", Name: "theme"}})
+	assert.EqualError(t, err, "failed to rollback operation setSetting: unsupported operation type: setSetting")
+	assert.ErrorIs(t, err, ErrRollbackFailed)
+	assert.ErrorIs(t, err, ErrUnsupportedOperation)
+
+	// When undoing a failed migration fails too, both errors are returned
+	require.NoError(t, m.RegisterOperation("setSetting", &irreversibleHandler{}))
+	err = m.applyDownOperations(ctx, "000003_settings", []Operation{
+		{Type: "setSetting", Name: "theme", Options: map[string]interface{}{"value": "dark"}},
+		{Type: "setSettings", Name: "language"},
+	})
+	require.ErrorAs(t, err, &migrationErr)
+	assert.Equal(t, 1, migrationErr.OperationIndex)
+	assert.Equal(t, DirectionDown, migrationErr.Direction)
+	assert.ErrorIs(t, err, ErrUnsupportedOperation)
+	assert.ErrorIs(t, err, ErrRollbackFailed)
+	assert.ErrorContains(t, err, "failed to restore migration 000003_settings after failed rollback")
+}
+
+// irreversibleHandler is a settingHandler whose operations can't be rolled back.
+type irreversibleHandler struct {
+	settingHandler
+}
+
+func (h *irreversibleHandler) Rollback(ctx context.Context, db arangodb.Database, operation OperationResult) error {
+	return fmt.Errorf("setting %s can't be removed", operation.Name)
+}
@@ -0,0 +1,291 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// graphDefinitionOptions are the createGraph options graphDefinition checks itself
+// instead of decoding them into the graph definition.
+var graphDefinitionOptions = []string{"edgeDefinitions", "orphanedCollections", "orphanCollections", "satellites"}
+
+// graphDefinition converts the options of a createGraph operation to the driver's
+// graph definition and create options. Besides the edge definitions and orphan
+// collections, the options of SmartGraphs, EnterpriseGraphs and SatelliteGraphs can
+// be set:
+//
+//	{
+//		"type": "createGraph",
+//		"name": "social",
+//		"options": {
+//		  "edgeDefinitions": [
+//		    {"collection": "follows", "from": ["users"], "to": ["users"]}
+//		  ],
+//		  "isSmart": true,
+//		  "smartGraphAttribute": "region",
+//		  "isDisjoint": true,
+//		  "numberOfShards": 9,
+//		  "replicationFactor": 2,
+//		  "satellites": ["countries"]
+//		}
+//	}
+func graphDefinition(options map[string]interface{}) (*arangodb.GraphDefinition, *arangodb.CreateGraphOptions, error) {
+	edgeDefinitions, err := parseEdgeDefinitions(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orphanedCollections, err := getOrphanedCollections(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	satellites, err := graphSatellites(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The edge definitions and orphan collections are checked above, and satellites
+	// are a create option rather than part of the definition. Unknown options were
+	// always ignored, so they are only reported with a warning.
+	definition := &arangodb.GraphDefinition{}
+	err = decodeKnownOptions(options, definition, graphDefinitionOptions...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	definition.EdgeDefinitions = edgeDefinitions
+	definition.OrphanCollections = orphanedCollections
+	return definition, &arangodb.CreateGraphOptions{Satellites: satellites}, nil
+}
+
+// graphSatellites returns the satellites option of an operation that changes a graph.
+func graphSatellites(options map[string]interface{}) ([]string, error) {
+	if _, exists := options["satellites"]; !exists {
+		return nil, nil
+	}
+	satellites, ok := getSlice[string](options, "satellites")
+	if !ok {
+		return nil, fmt.Errorf("satellites option not a string array")
+	}
+	return satellites, nil
+}
+
+// replaceEdgeDefinitionWithTracking changes the vertex collections of an edge
+// definition and records the previous ones for rollback.
+//
+//	{
+//		"type": "replaceEdgeDefinition",
+//		"name": "social",
+//		"options": {
+//		  "collection": "follows",
+//		  "from": ["users"],
+//		  "to": ["users", "organizations"]
+//		}
+//	}
+func replaceEdgeDefinitionWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:         "replaceEdgeDefinition",
+		Name:         name,
+		Options:      options,
+		Result:       make(map[string]interface{}),
+		RollbackData: make(map[string]interface{}),
+	}
+
+	edgeDefinition, err := parseEdgeDefinition(edgeDefinitionOptions(options))
+	if err != nil {
+		return result, err
+	}
+
+	satellites, err := graphSatellites(options)
+	if err != nil {
+		return result, err
+	}
+
+	graph, err := db.Graph(ctx, name, &arangodb.GetGraphOptions{})
+	if err != nil {
+		return result, fmt.Errorf("failed to get graph '%s': %w", name, err)
+	}
+
+	// Store the current edge definition for rollback
+	found := false
+	for _, current := range graph.EdgeDefinitions() {
+		if current.Collection == edgeDefinition.Collection {
+			result.RollbackData["originalEdgeDefinition"] = current
+			found = true
+			break
+		}
+	}
+	if !found {
+		return result, fmt.Errorf("graph '%s' has no edge definition for collection '%s'", name, edgeDefinition.Collection)
+	}
+
+	err = replaceEdgeDefinition(ctx, graph, edgeDefinition, satellites)
+	if err != nil {
+		return result, err
+	}
+
+	result.Result["graphName"] = name
+	result.Result["collection"] = edgeDefinition.Collection
+	return result, nil
+}
+
+func replaceEdgeDefinition(ctx context.Context, graph arangodb.Graph, edgeDefinition arangodb.EdgeDefinition, satellites []string) error {
+	_, err := graph.ReplaceEdgeDefinition(ctx, edgeDefinition.Collection, edgeDefinition.From, edgeDefinition.To, &arangodb.ReplaceEdgeOptions{
+		Satellites: satellites,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replace edge definition: %w", err)
+	}
+	return nil
+}
+
+// restoreEdgeDefinition replaces an edge definition with the one recorded before a
+// replaceEdgeDefinition operation.
+func restoreEdgeDefinition(ctx context.Context, db arangodb.Database, operation OperationResult) error {
+	original, ok := operation.RollbackData["originalEdgeDefinition"]
+	if !ok {
+		return fmt.Errorf("cannot rollback edge definition replacement - no original edge definition available")
+	}
+
+	var edgeDefinition arangodb.EdgeDefinition
+	err := remarshal(original, &edgeDefinition)
+	if err != nil {
+		return fmt.Errorf("failed to decode original edge definition: %w", err)
+	}
+
+	graph, err := db.Graph(ctx, operation.Name, &arangodb.GetGraphOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get graph '%s': %w", operation.Name, err)
+	}
+
+	return replaceEdgeDefinition(ctx, graph, edgeDefinition, nil)
+}
+
+// addVertexCollectionWithTracking adds a vertex collection to a graph as an orphan
+// collection. The collection is created if it doesn't exist, and whether it was is
+// recorded so rolling back only drops a collection the operation created.
+//
+//	{
+//		"type": "addVertexCollection",
+//		"name": "social",
+//		"options": {
+//		  "collection": "organizations"
+//		}
+//	}
+func addVertexCollectionWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:    "addVertexCollection",
+		Name:    name,
+		Options: options,
+		Result:  make(map[string]interface{}),
+	}
+
+	collection, ok := options["collection"].(string)
+	if !ok {
+		return result, fmt.Errorf("collection option missing or not a string")
+	}
+
+	satellites, err := graphSatellites(options)
+	if err != nil {
+		return result, err
+	}
+
+	exists, err := db.CollectionExists(ctx, collection)
+	if err != nil {
+		return result, fmt.Errorf("failed to check if collection '%s' exists: %w", collection, err)
+	}
+
+	err = addVertexCollection(ctx, db, name, collection, satellites)
+	if err != nil {
+		return result, err
+	}
+
+	result.Result["graphName"] = name
+	result.Result["collection"] = collection
+	result.Result["collectionCreated"] = !exists
+	return result, nil
+}
+
+// rollbackAddVertexCollection removes a vertex collection from a graph again, and
+// drops the collection if the operation created it.
+func rollbackAddVertexCollection(ctx context.Context, db arangodb.Database, operation OperationResult) error {
+	collection, _ := operation.Options["collection"].(string)
+	err := removeVertexCollection(ctx, db, operation.Name, collection)
+	if err != nil {
+		return err
+	}
+
+	if created, _ := operation.Result["collectionCreated"].(bool); created {
+		return deleteCollection(ctx, db, collection)
+	}
+	return nil
+}
+
+func addVertexCollection(ctx context.Context, db arangodb.Database, name string, collection string, satellites []string) error {
+	graph, err := db.Graph(ctx, name, &arangodb.GetGraphOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get graph '%s': %w", name, err)
+	}
+
+	_, err = graph.CreateVertexCollection(ctx, collection, &arangodb.CreateVertexCollectionOptions{
+		Satellites: satellites,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add vertex collection '%s': %w", collection, err)
+	}
+
+	return nil
+}
+
+// removeVertexCollectionWithTracking removes an orphan collection from a graph. The
+// collection itself is kept, so rolling back adds it to the graph again.
+//
+//	{
+//		"type": "removeVertexCollection",
+//		"name": "social",
+//		"options": {
+//		  "collection": "organizations"
+//		}
+//	}
+func removeVertexCollectionWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:    "removeVertexCollection",
+		Name:    name,
+		Options: options,
+		Result:  make(map[string]interface{}),
+	}
+
+	collection, ok := options["collection"].(string)
+	if !ok {
+		return result, fmt.Errorf("collection option missing or not a string")
+	}
+
+	err := removeVertexCollection(ctx, db, name, collection)
+	if err != nil {
+		return result, err
+	}
+
+	result.Result["graphName"] = name
+	result.Result["collection"] = collection
+	return result, nil
+}
+
+func removeVertexCollection(ctx context.Context, db arangodb.Database, name string, collection string) error {
+	graph, err := db.Graph(ctx, name, &arangodb.GetGraphOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get graph '%s': %w", name, err)
+	}
+
+	dropCollection := false
+	_, err = graph.DeleteVertexCollection(ctx, collection, &arangodb.DeleteVertexCollectionOptions{
+		DropCollection: &dropCollection,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove vertex collection '%s': %w", collection, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package migrator
+
+import (
+	"context"
+	"time"
+)
+
+// Direction tells whether a migration is applied or rolled back.
+type Direction string
+
+const (
+	// DirectionUp is used while applying the 'up' list of a migration.
+	DirectionUp Direction = "up"
+
+	// DirectionDown is used while rolling back a migration, either with its 'down'
+	// list or from its recorded operation results.
+	DirectionDown Direction = "down"
+)
+
+// MigrationEvent describes a migration for the BeforeMigration and AfterMigration hooks.
+type MigrationEvent struct {
+	// MigrationNumber is the full migration number, like "000001_create_users".
+	MigrationNumber string
+
+	// Description is the description of the migration. It is only set when the
+	// migration is applied.
+	Description string
+
+	Direction Direction
+
+	// Duration is the time it took to apply or roll back the migration. It is only
+	// set for AfterMigration.
+	Duration time.Duration
+
+	// Err is the error the migration failed with. It is only set for AfterMigration.
+	Err error
+}
+
+// OperationEvent describes an operation for the BeforeOperation and AfterOperation hooks.
+type OperationEvent struct {
+	MigrationNumber string
+	Direction       Direction
+
+	// Index is the position of the operation in the 'up' or 'down' list.
+	Index int
+
+	Operation Operation
+
+	// Result is what the operation returned. It is only set for AfterOperation.
+	Result OperationResult
+
+	// Duration is the time it took to apply the operation. It is only set for
+	// AfterOperation.
+	Duration time.Duration
+
+	// Err is the error the operation failed with. It is only set for AfterOperation.
+	Err error
+}
+
+// RollbackEvent describes an applied operation that was rolled back for the
+// OnRollback hook.
+type RollbackEvent struct {
+	Operation OperationResult
+
+	// Err is the error rolling back the operation failed with.
+	Err error
+}
+
+// Hooks are called by a Migrator at each step of applying or rolling back migrations,
+// for example to emit metrics or audit logs. Hooks observe the migration and can't
+// stop it; nil hooks are skipped. They aren't called for dry runs.
+type Hooks struct {
+	// BeforeMigration is called before the operations of a migration are applied or
+	// rolled back.
+	BeforeMigration func(ctx context.Context, event MigrationEvent)
+
+	// AfterMigration is called after a migration was applied or rolled back, or
+	// failed. Without CommitEachMigration, applied migrations are only recorded once
+	// the whole batch succeeds.
+	AfterMigration func(ctx context.Context, event MigrationEvent)
+
+	// BeforeOperation is called before an operation of an 'up' or 'down' list is
+	// applied.
+	BeforeOperation func(ctx context.Context, event OperationEvent)
+
+	// AfterOperation is called after an operation of an 'up' or 'down' list was
+	// applied or failed. In transactional migrations, the operation is only
+	// committed when all operations of the migration succeed.
+	AfterOperation func(ctx context.Context, event OperationEvent)
+
+	// OnRollback is called for each applied operation that was rolled back from its
+	// recorded result, or that failed to roll back. This happens when a failed
+	// migration is rolled back, and when a migration is reverted without a 'down'
+	// list.
+	OnRollback func(ctx context.Context, event RollbackEvent)
+}
+
+// WithHooks sets the hooks of a Migrator. The hooks set by earlier WithHooks options
+// are kept unless hooks replaces them.
+func WithHooks(hooks Hooks) Option {
+	return func(m *Migrator) {
+		if hooks.BeforeMigration != nil {
+			m.hooks.BeforeMigration = hooks.BeforeMigration
+		}
+		if hooks.AfterMigration != nil {
+			m.hooks.AfterMigration = hooks.AfterMigration
+		}
+		if hooks.BeforeOperation != nil {
+			m.hooks.BeforeOperation = hooks.BeforeOperation
+		}
+		if hooks.AfterOperation != nil {
+			m.hooks.AfterOperation = hooks.AfterOperation
+		}
+		if hooks.OnRollback != nil {
+			m.hooks.OnRollback = hooks.OnRollback
+		}
+	}
+}
+
+// startMigration calls the BeforeMigration hook and returns a function that calls the
+// AfterMigration hook with the error the migration finished with.
+func (m *Migrator) startMigration(ctx context.Context, event MigrationEvent) func(err error) error {
+	if m.hooks.BeforeMigration != nil {
+		m.hooks.BeforeMigration(ctx, event)
+	}
+
+	start := time.Now()
+	return func(err error) error {
+		if m.hooks.AfterMigration != nil {
+			event.Duration = time.Since(start)
+			event.Err = err
+			m.hooks.AfterMigration(ctx, event)
+		}
+		return err
+	}
+}
+
+// runOperation applies an operation with apply between the BeforeOperation and
+// AfterOperation hooks.
+func (m *Migrator) runOperation(ctx context.Context, event OperationEvent, apply func(ctx context.Context, operation Operation) (OperationResult, error)) (OperationResult, error) {
+	if m.hooks.BeforeOperation != nil {
+		m.hooks.BeforeOperation(ctx, event)
+	}
+
+	start := time.Now()
+	operationResult, err := apply(ctx, event.Operation)
+	if err == nil {
+		m.options.logger().Debug("applied operation", "migration", event.MigrationNumber, "operation", event.Operation.Type, "name", event.Operation.Name, "duration", time.Since(start))
+	}
+
+	if m.hooks.AfterOperation != nil {
+		event.Result = operationResult
+		event.Duration = time.Since(start)
+		event.Err = err
+		m.hooks.AfterOperation(ctx, event)
+	}
+	return operationResult, err
+}
+
+// rolledBack calls the OnRollback hook.
+func (m *Migrator) rolledBack(ctx context.Context, operation OperationResult, err error) {
+	if m.hooks.OnRollback != nil {
+		m.hooks.OnRollback(ctx, RollbackEvent{Operation: operation, Err: err})
+	}
+}
@@ -0,0 +1,160 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FramnkRulez/go-arangodb-migrator/pkg/migrator/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHooks returns hooks that append a line for every call to events.
+func recordingHooks(events *[]string) Hooks {
+	return Hooks{
+		BeforeMigration: func(ctx context.Context, event MigrationEvent) {
+			*events = append(*events, fmt.Sprintf("before migration %s %s", event.Direction, event.MigrationNumber))
+		},
+		AfterMigration: func(ctx context.Context, event MigrationEvent) {
+			*events = append(*events, fmt.Sprintf("after migration %s %s err=%t", event.Direction, event.MigrationNumber, event.Err != nil))
+		},
+		BeforeOperation: func(ctx context.Context, event OperationEvent) {
+			*events = append(*events, fmt.Sprintf("before operation %s %d %s %s", event.Direction, event.Index, event.Operation.Type, event.Operation.Name))
+		},
+		AfterOperation: func(ctx context.Context, event OperationEvent) {
+			*events = append(*events, fmt.Sprintf("after operation %s %d %s %s err=%t", event.Direction, event.Index, event.Operation.Type, event.Operation.Name, event.Err != nil))
+		},
+		OnRollback: func(ctx context.Context, event RollbackEvent) {
+			*events = append(*events, fmt.Sprintf("rollback %s %s err=%t", event.Operation.Type, event.Operation.Name, event.Err != nil))
+		},
+	}
+}
+
+// TestMigrationHooks tests that the hooks of a Migrator are called around operations
+func TestMigrationHooks(t *testing.T) {
+	ctx := context.Background()
+
+	var events []string
+	var after OperationEvent
+	m := New(nil, WithHooks(recordingHooks(&events)), WithHooks(Hooks{
+		AfterOperation: func(ctx context.Context, event OperationEvent) {
+			after = event
+		},
+	}))
+	require.NoError(t, m.RegisterOperation("setSetting", &settingHandler{}))
+
+	// Later hooks replace earlier ones and keep the rest
+	assert.NotNil(t, m.hooks.BeforeOperation)
+	assert.NotNil(t, m.hooks.OnRollback)
+
+	options := map[string]interface{}{"value": "dark"}
+	event := OperationEvent{MigrationNumber: "000001_settings", Direction: DirectionUp, Index: 2, Operation: Operation{Type: "setSetting", Name: "theme", Options: options}}
+	result, err := m.runOperation(ctx, event, m.applyOperation)
+	require.NoError(t, err)
+	assert.Equal(t, "000001_settings", after.MigrationNumber)
+	assert.Equal(t, 2, after.Index)
+	assert.Equal(t, result, after.Result)
+	assert.NoError(t, after.Err)
+
+	event.Operation.Options = nil
+	_, err = m.runOperation(ctx, event, m.applyOperation)
+	assert.Error(t, err)
+	assert.Equal(t, err, after.Err)
+
+	err = m.autoRollback(ctx, []OperationResult{result, {Type: "setSettings", Name: "font"}})
+	assert.Error(t, err)
+
+	finish := m.startMigration(ctx, MigrationEvent{MigrationNumber: "000001_settings", Direction: DirectionDown})
+	assert.Equal(t, err, finish(err))
+
+	assert.Equal(t, []string{
+		"before operation up 2 setSetting theme",
+		"before operation up 2 setSetting theme",
+		"rollback setSettings font err=true",
+		"before migration down 000001_settings",
+		"after migration down 000001_settings err=true",
+	}, events)
+}
+
+func TestMigratorHooks(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_hooks")
+
+	tempDir := t.TempDir()
+
+	migration1 := `{
+		"description": "Create users",
+		"up": [
+			{"type": "createCollection", "name": "users", "options": {"type": "document"}}
+		],
+		"down": [
+			{"type": "deleteCollection", "name": "users"}
+		]
+	}`
+	migration2 := `{
+		"description": "Create posts",
+		"up": [
+			{"type": "createCollection", "name": "posts", "options": {"type": "document"}},
+			{"type": "createPersistentIndex", "name": "idx_posts_slug", "options": {"collection": "posts", "fields": ["slug"]}}
+		]
+	}`
+	err := os.WriteFile(filepath.Join(tempDir, "000001_users.json"), []byte(migration1), 0644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "000002_posts.json"), []byte(migration2), 0644)
+	require.NoError(t, err)
+
+	var events []string
+	m := New(db, WithHooks(recordingHooks(&events)), WithMigrationOptions(MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+	}))
+
+	err = m.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"before migration up 000001_users",
+		"before operation up 0 createCollection users",
+		"after operation up 0 createCollection users err=false",
+		"after migration up 000001_users err=false",
+		"before migration up 000002_posts",
+		"before operation up 0 createCollection posts",
+		"after operation up 0 createCollection posts err=false",
+		"before operation up 1 createPersistentIndex idx_posts_slug",
+		"after operation up 1 createPersistentIndex idx_posts_slug err=false",
+		"after migration up 000002_posts err=false",
+	}, events)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, MigrationStateApplied, statuses[1].State)
+
+	// The migration without a down list is reverted from its recorded results
+	events = nil
+	err = m.Down(ctx, "0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"before migration down 000002_posts",
+		"rollback createPersistentIndex idx_posts_slug err=false",
+		"rollback createCollection posts err=false",
+		"after migration down 000002_posts err=false",
+		"before migration down 000001_users",
+		"before operation down 0 deleteCollection users",
+		"after operation down 0 deleteCollection users err=false",
+		"after migration down 000001_users err=false",
+	}, events)
+
+	plan, err := m.Plan(ctx)
+	require.NoError(t, err)
+	assert.Len(t, plan.Migrations, 2)
+	assert.Len(t, events, 8, "planning doesn't call hooks")
+}
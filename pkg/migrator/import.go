@@ -0,0 +1,372 @@
+package migrator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"math"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/arangodb/go-driver/v2/arangodb/shared"
+)
+
+// defaultImportBatchSize is the number of documents sent per request by
+// importDocuments unless the batchSize option is set.
+const defaultImportBatchSize = 1000
+
+// importFileExtensions are the file extensions importDocuments can read documents
+// from. Files with these extensions in the migration folder are not migration files.
+var importFileExtensions = []string{".jsonl", ".ndjson", ".csv"}
+
+// importOverwriteModes maps the onDuplicate option of importDocuments to the driver's
+// overwrite modes. The default "error" fails on documents whose key already exists.
+var importOverwriteModes = map[string]arangodb.CollectionDocumentCreateOverwriteMode{
+	"error":   arangodb.CollectionDocumentCreateOverwriteModeConflict,
+	"update":  arangodb.CollectionDocumentCreateOverwriteModeUpdate,
+	"replace": arangodb.CollectionDocumentCreateOverwriteModeReplace,
+	"ignore":  arangodb.CollectionDocumentCreateOverwriteModeIgnore,
+}
+
+// importDocumentsWithTracking creates many documents with batch requests. The
+// documents are given inline or read from a JSONL or CSV file relative to the
+// migration folder. The keys of created documents and the previous version of
+// overwritten documents are recorded for rollback; inline documents are not recorded.
+//
+//	{
+//		"type": "importDocuments",
+//		"name": "countries",
+//		"options": {
+//		  "file": "data/countries.jsonl",
+//		  "onDuplicate": "replace",
+//		  "batchSize": 500
+//		}
+//	}
+func importDocumentsWithTracking(ctx context.Context, db arangodb.Database, log *slog.Logger, fsys fs.FS, dir string, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:         "importDocuments",
+		Name:         name,
+		Options:      omitOptions(options, "documents"),
+		Result:       make(map[string]interface{}),
+		RollbackData: make(map[string]interface{}),
+	}
+
+	err := validateImportDocumentsOptions(options)
+	if err != nil {
+		return result, err
+	}
+
+	documents, err := importDocumentsSource(fsys, dir, options)
+	if err != nil {
+		return result, err
+	}
+
+	coll, err := db.GetCollection(ctx, name, &arangodb.GetCollectionOptions{})
+	if err != nil {
+		return result, fmt.Errorf("failed to get collection '%s' for import: %w", name, err)
+	}
+
+	onDuplicate := "error"
+	if value, ok := options["onDuplicate"].(string); ok {
+		onDuplicate = value
+	}
+
+	batchSize := defaultImportBatchSize
+	if value, ok := options["batchSize"].(float64); ok {
+		batchSize = int(value)
+	}
+
+	// Documents that already exist are not created by the import. They are left as
+	// they are or overwritten, and in that case restored on rollback.
+	existing, err := existingDocuments(ctx, db, name, documents)
+	if err != nil {
+		return result, err
+	}
+	existingKeys := make(map[string]bool)
+	for _, document := range existing {
+		existingKeys[document["_key"].(string)] = true
+	}
+	if onDuplicate == "update" || onDuplicate == "replace" {
+		result.RollbackData["originalDocuments"] = existing
+	}
+
+	createOptions := &arangodb.CollectionDocumentCreateOptions{}
+	if onDuplicate != "error" {
+		overwriteMode := importOverwriteModes[onDuplicate]
+		createOptions.OverwriteMode = overwriteMode.New()
+	}
+
+	createdKeys := []string{}
+	var importErr error
+	for start := 0; start < len(documents) && importErr == nil; start += batchSize {
+		batch := documents[start:min(start+batchSize, len(documents))]
+
+		reader, err := coll.CreateDocumentsWithOptions(ctx, batch, createOptions)
+		if err != nil {
+			importErr = fmt.Errorf("failed to import documents: %w", err)
+			break
+		}
+
+		// Documents of a batch are created independently, so the whole response is
+		// read to know which ones have to be removed again
+		for {
+			meta, err := reader.Read()
+			if shared.IsNoMoreDocuments(err) {
+				break
+			}
+			if err != nil {
+				if importErr == nil {
+					importErr = fmt.Errorf("failed to import document: %w", err)
+				}
+				continue
+			}
+			if !existingKeys[meta.Key] {
+				createdKeys = append(createdKeys, meta.Key)
+			}
+		}
+	}
+
+	result.RollbackData["createdKeys"] = createdKeys
+
+	if importErr != nil {
+		// Undo the batches imported before the failure, the operation is not recorded
+		rollbackErr := rollbackImportDocuments(ctx, db, result)
+		if rollbackErr != nil {
+			log.Error("failed to remove documents of failed import", "operation", "importDocuments", "name", name, "error", rollbackErr)
+		}
+		return result, importErr
+	}
+
+	result.Result["documentsImported"] = len(documents)
+	result.Result["documentsCreated"] = len(createdKeys)
+	return result, nil
+}
+
+// rollbackImportDocuments removes the documents created by an import and restores
+// the documents it overwrote.
+func rollbackImportDocuments(ctx context.Context, db arangodb.Database, operation OperationResult) error {
+	createdKeys, ok := operation.RollbackData["createdKeys"]
+	if !ok {
+		return fmt.Errorf("cannot rollback document import - no created keys available")
+	}
+
+	_, err := executeAQL(ctx, db, "FOR k IN @keys REMOVE k IN @@collection OPTIONS { ignoreErrors: true }",
+		map[string]interface{}{"keys": createdKeys, "@collection": operation.Name}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove imported documents from '%s': %w", operation.Name, err)
+	}
+
+	if originals, ok := operation.RollbackData["originalDocuments"]; ok {
+		_, err := executeAQL(ctx, db, "FOR d IN @documents REPLACE d IN @@collection",
+			map[string]interface{}{"documents": originals, "@collection": operation.Name}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to restore documents overwritten in '%s': %w", operation.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// existingDocuments returns the documents of a collection that have the key of one
+// of the given documents.
+func existingDocuments(ctx context.Context, db arangodb.Database, name string, documents []map[string]interface{}) ([]map[string]interface{}, error) {
+	var keys []string
+	for _, document := range documents {
+		if key, ok := document["_key"].(string); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	existing := []map[string]interface{}{}
+	if len(keys) == 0 {
+		return existing, nil
+	}
+
+	cursor, err := db.Query(ctx, "FOR d IN @@collection FILTER d._key IN @keys RETURN d", &arangodb.QueryOptions{
+		BindVars: map[string]interface{}{"@collection": name, "keys": keys},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read existing documents of collection '%s': %w", name, err)
+	}
+	defer cursor.Close()
+
+	for cursor.HasMore() {
+		var document map[string]interface{}
+		_, err := cursor.ReadDocument(ctx, &document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read existing documents of collection '%s': %w", name, err)
+		}
+		existing = append(existing, document)
+	}
+
+	return existing, nil
+}
+
+// importDocumentsSource returns the documents of an importDocuments operation from
+// its documents or file option.
+func importDocumentsSource(fsys fs.FS, dir string, options map[string]interface{}) ([]map[string]interface{}, error) {
+	if file, ok := options["file"].(string); ok {
+		if fsys == nil {
+			return nil, fmt.Errorf("cannot read import file %s without a migration folder", file)
+		}
+		return readImportFile(fsys, path.Join(dir, file))
+	}
+
+	var documents []map[string]interface{}
+	for i, document := range options["documents"].([]interface{}) {
+		document, ok := document.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("document %d is not an object", i)
+		}
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
+
+// readImportFile reads the documents of a JSONL file, with one document per line, or
+// a CSV file, whose header row names the attributes. Values read from CSV files are
+// strings.
+func readImportFile(fsys fs.FS, name string) ([]map[string]interface{}, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import file: %w", err)
+	}
+
+	switch path.Ext(name) {
+	case ".jsonl", ".ndjson":
+		return parseJSONLines(data)
+	case ".csv":
+		return parseCSV(data)
+	default:
+		return nil, fmt.Errorf("unsupported import file type: %s", name)
+	}
+}
+
+func parseJSONLines(data []byte) ([]map[string]interface{}, error) {
+	var documents []map[string]interface{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var document map[string]interface{}
+		err := json.Unmarshal([]byte(text), &document)
+		if err != nil || document == nil {
+			return nil, fmt.Errorf("line %d is not a JSON object: %w", line, err)
+		}
+		documents = append(documents, document)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import file: %w", err)
+	}
+
+	return documents, nil
+}
+
+func parseCSV(data []byte) ([]map[string]interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	var documents []map[string]interface{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV file: %w", err)
+		}
+
+		document := make(map[string]interface{}, len(header))
+		for i, attribute := range header {
+			document[attribute] = record[i]
+		}
+		documents = append(documents, document)
+	}
+
+	return documents, nil
+}
+
+// validateImportDocumentsOptions checks the options of an importDocuments operation.
+func validateImportDocumentsOptions(options map[string]interface{}) error {
+	documents, hasDocuments := options["documents"]
+	file, hasFile := options["file"]
+
+	if hasDocuments == hasFile {
+		return fmt.Errorf("exactly one of the documents and file options must be set")
+	}
+
+	if hasDocuments {
+		documents, ok := documents.([]interface{})
+		if !ok {
+			return fmt.Errorf("documents option not an array")
+		}
+		for i, document := range documents {
+			if _, ok := document.(map[string]interface{}); !ok {
+				return fmt.Errorf("document %d is not an object", i)
+			}
+		}
+	}
+
+	if hasFile {
+		file, ok := file.(string)
+		if !ok || file == "" {
+			return fmt.Errorf("file option not a string")
+		}
+		if !slices.Contains(importFileExtensions, path.Ext(file)) {
+			return fmt.Errorf("file option must be a .jsonl, .ndjson or .csv file")
+		}
+	}
+
+	if value, exists := options["onDuplicate"]; exists {
+		onDuplicate, _ := value.(string)
+		if _, ok := importOverwriteModes[onDuplicate]; !ok {
+			return fmt.Errorf("onDuplicate option must be one of error, update, replace or ignore")
+		}
+	}
+
+	if value, exists := options["batchSize"]; exists {
+		batchSize, ok := value.(float64)
+		if !ok || batchSize < 1 || batchSize != math.Trunc(batchSize) {
+			return fmt.Errorf("batchSize option not a positive integer")
+		}
+	}
+
+	return nil
+}
+
+// validateImportFile checks that the file of an importDocuments operation can be read
+// from the migration folder. Other operations are not checked.
+func validateImportFile(fsys fs.FS, dir string, operation Operation) error {
+	if operation.Type != "importDocuments" {
+		return nil
+	}
+
+	file, ok := operation.Options["file"].(string)
+	if !ok {
+		return nil
+	}
+
+	_, err := readImportFile(fsys, path.Join(dir, file))
+	return err
+}
@@ -0,0 +1,722 @@
+package migrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/arangodb/go-driver/v2/arangodb/shared"
+	"github.com/arangodb/go-driver/v2/connection"
+)
+
+// createIndexWithTracking creates an index with one of the create*Index functions and
+// records what is needed to delete it again. The create functions report whether the
+// index was created; an identical index that already existed is not deleted on
+// rollback.
+func createIndexWithTracking(ctx context.Context, db arangodb.Database, operationType string, name string, options map[string]interface{},
+	create func(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (bool, error)) (OperationResult, error) {
+	result := OperationResult{
+		Type:    operationType,
+		Name:    name,
+		Options: options,
+		Result:  make(map[string]interface{}),
+	}
+
+	created, err := create(ctx, db, name, options)
+	if err != nil {
+		return result, err
+	}
+
+	result.Result["indexName"] = name
+	result.Result["collection"] = options["collection"]
+	result.Result["created"] = created
+	return result, nil
+}
+
+// rollbackCreateIndex deletes the index created by a create*Index operation. Results
+// recorded before it was tracked whether the index was created are deleted too.
+func rollbackCreateIndex(ctx context.Context, db arangodb.Database, operation OperationResult) error {
+	if created, ok := operation.Result["created"].(bool); ok && !created {
+		return nil
+	}
+	return deleteIndex(ctx, db, operation.Name, operation.Options)
+}
+
+func deleteIndexWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:    "deleteIndex",
+		Name:    name,
+		Options: options,
+		Result:  make(map[string]interface{}),
+	}
+
+	err := deleteIndex(ctx, db, name, options)
+	if err != nil {
+		return result, err
+	}
+
+	result.Result["indexName"] = name
+	result.Result["collection"] = options["collection"]
+	return result, nil
+}
+
+// indexCollection returns the collection named by the collection option of an index
+// operation.
+func indexCollection(ctx context.Context, db arangodb.Database, options map[string]interface{}) (arangodb.Collection, error) {
+	collName, ok := options["collection"].(string)
+	if !ok {
+		return nil, fmt.Errorf("collection name missing or not a string")
+	}
+
+	coll, err := db.GetCollection(ctx, collName, &arangodb.GetCollectionOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection '%s' for index creation: %w", collName, err)
+	}
+
+	return coll, nil
+}
+
+//	{
+//		"type": "createPersistentIndex",
+//		"name": "idx_unique_usernames",
+//		"options": {
+//		  "collection": "users",
+//		  "fields": ["normalizedUserName"],
+//		  "unique": true,
+//		  "sparse": true,
+//		  "storedValues": ["displayName"],
+//		  "cacheEnabled": true
+//		}
+//	}
+func createPersistentIndex(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (bool, error) {
+	fields, indexOptions, err := persistentIndexOptions(name, options)
+	if err != nil {
+		return false, err
+	}
+
+	coll, err := indexCollection(ctx, db, options)
+	if err != nil {
+		return false, err
+	}
+
+	_, created, err := coll.EnsurePersistentIndex(ctx, fields, &indexOptions)
+	if err != nil {
+		return false, fmt.Errorf("failed to create persistent index: %w", err)
+	}
+
+	return created, nil
+}
+
+func persistentIndexOptions(name string, options map[string]interface{}) ([]string, arangodb.CreatePersistentIndexOptions, error) {
+	indexOptions := arangodb.CreatePersistentIndexOptions{
+		Name: name,
+	}
+
+	fields, err := indexFields(options)
+	if err != nil {
+		return nil, indexOptions, err
+	}
+
+	err = setBoolOptions(options, map[string]**bool{
+		"unique":       &indexOptions.Unique,
+		"sparse":       &indexOptions.Sparse,
+		"deduplicate":  &indexOptions.Deduplicate,
+		"estimates":    &indexOptions.Estimates,
+		"cacheEnabled": &indexOptions.CacheEnabled,
+		"inBackground": &indexOptions.InBackground,
+	})
+	if err != nil {
+		return nil, indexOptions, err
+	}
+
+	indexOptions.StoredValues, err = stringSliceOption(options, "storedValues")
+	if err != nil {
+		return nil, indexOptions, err
+	}
+
+	return fields, indexOptions, nil
+}
+
+//	{
+//		"type": "createGeoIndex",
+//		"name": "idx_event_location",
+//		"options": {
+//		  "collection": "events",
+//		  "fields": ["location"]
+//		}
+//	}
+func createGeoIndex(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (bool, error) {
+	fields, indexOptions, err := geoIndexOptions(name, options)
+	if err != nil {
+		return false, err
+	}
+
+	coll, err := indexCollection(ctx, db, options)
+	if err != nil {
+		return false, err
+	}
+
+	_, created, err := coll.EnsureGeoIndex(ctx, fields, &indexOptions)
+	if err != nil {
+		return false, fmt.Errorf("failed to create geo index: %w", err)
+	}
+
+	return created, nil
+}
+
+func geoIndexOptions(name string, options map[string]interface{}) ([]string, arangodb.CreateGeoIndexOptions, error) {
+	indexOptions := arangodb.CreateGeoIndexOptions{
+		Name: name,
+	}
+
+	fields, err := indexFields(options)
+	if err != nil {
+		return nil, indexOptions, err
+	}
+
+	err = setBoolOptions(options, map[string]**bool{
+		"geoJson":        &indexOptions.GeoJSON,
+		"legacyPolygons": &indexOptions.LegacyPolygons,
+		"inBackground":   &indexOptions.InBackground,
+	})
+	if err != nil {
+		return nil, indexOptions, err
+	}
+
+	return fields, indexOptions, nil
+}
+
+//	{
+//		"type": "createTTLIndex",
+//		"name": "idx_sessions_expiry",
+//		"options": {
+//		  "collection": "sessions",
+//		  "fields": ["createdAt"],
+//		  "expireAfter": 3600
+//		}
+//	}
+func createTTLIndex(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (bool, error) {
+	fields, expireAfter, indexOptions, err := ttlIndexOptions(name, options)
+	if err != nil {
+		return false, err
+	}
+
+	coll, err := indexCollection(ctx, db, options)
+	if err != nil {
+		return false, err
+	}
+
+	_, created, err := coll.EnsureTTLIndex(ctx, fields, expireAfter, &indexOptions)
+	if err != nil {
+		return false, fmt.Errorf("failed to create TTL index: %w", err)
+	}
+
+	return created, nil
+}
+
+func ttlIndexOptions(name string, options map[string]interface{}) ([]string, int, arangodb.CreateTTLIndexOptions, error) {
+	indexOptions := arangodb.CreateTTLIndexOptions{
+		Name: name,
+	}
+
+	fields, err := indexFields(options)
+	if err != nil {
+		return nil, 0, indexOptions, err
+	}
+	if len(fields) != 1 {
+		return nil, 0, indexOptions, fmt.Errorf("fields option must contain exactly one field")
+	}
+
+	expireAfter, ok := options["expireAfter"].(float64)
+	if !ok || expireAfter < 0 || expireAfter != math.Trunc(expireAfter) {
+		return nil, 0, indexOptions, fmt.Errorf("expireAfter option missing or not a non-negative number of seconds")
+	}
+
+	err = setBoolOptions(options, map[string]**bool{
+		"inBackground": &indexOptions.InBackground,
+	})
+	if err != nil {
+		return nil, 0, indexOptions, err
+	}
+
+	return fields, int(expireAfter), indexOptions, nil
+}
+
+//	{
+//		"type": "createMDIIndex",
+//		"name": "idx_events_time_range",
+//		"options": {
+//		  "collection": "events",
+//		  "fields": ["start", "end"],
+//		  "fieldValueTypes": "double",
+//		  "prefixFields": ["venue"]
+//		}
+//	}
+func createMDIIndex(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (bool, error) {
+	fields, indexOptions, err := mdiIndexOptions(name, options)
+	if err != nil {
+		return false, err
+	}
+
+	coll, err := indexCollection(ctx, db, options)
+	if err != nil {
+		return false, err
+	}
+
+	// Prefix fields make it an mdi-prefixed index
+	var created bool
+	if len(indexOptions.PrefixFields) > 0 {
+		_, created, err = coll.EnsureMDIPrefixedIndex(ctx, fields, &indexOptions)
+	} else {
+		_, created, err = coll.EnsureMDIIndex(ctx, fields, &indexOptions.CreateMDIIndexOptions)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create MDI index: %w", err)
+	}
+
+	return created, nil
+}
+
+func mdiIndexOptions(name string, options map[string]interface{}) ([]string, arangodb.CreateMDIPrefixedIndexOptions, error) {
+	indexOptions := arangodb.CreateMDIPrefixedIndexOptions{
+		CreateMDIIndexOptions: arangodb.CreateMDIIndexOptions{
+			Name:            name,
+			FieldValueTypes: arangodb.MDIDoubleFieldType,
+		},
+	}
+
+	fields, err := indexFields(options)
+	if err != nil {
+		return nil, indexOptions, err
+	}
+
+	if value, exists := options["fieldValueTypes"]; exists {
+		fieldValueTypes, ok := value.(string)
+		if !ok {
+			return nil, indexOptions, fmt.Errorf("fieldValueTypes option not a string")
+		}
+		indexOptions.FieldValueTypes = arangodb.MDIFieldType(fieldValueTypes)
+	}
+
+	err = setBoolOptions(options, map[string]**bool{
+		"unique": &indexOptions.Unique,
+		"sparse": &indexOptions.Sparse,
+	})
+	if err != nil {
+		return nil, indexOptions, err
+	}
+
+	indexOptions.StoredValues, err = stringSliceOption(options, "storedValues")
+	if err != nil {
+		return nil, indexOptions, err
+	}
+
+	indexOptions.PrefixFields, err = stringSliceOption(options, "prefixFields")
+	if err != nil {
+		return nil, indexOptions, err
+	}
+
+	return fields, indexOptions, nil
+}
+
+//	{
+//		"type": "createInvertedIndex",
+//		"name": "idx_posts_search",
+//		"options": {
+//		  "collection": "posts",
+//		  "analyzer": "text_en",
+//		  "fields": ["title", {"name": "tags", "analyzer": "identity"}],
+//		  "primarySort": {"fields": [{"field": "createdAt", "direction": "desc"}]}
+//		}
+//	}
+func createInvertedIndex(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (bool, error) {
+	indexOptions, err := invertedIndexOptions(name, options)
+	if err != nil {
+		return false, err
+	}
+
+	coll, err := indexCollection(ctx, db, options)
+	if err != nil {
+		return false, err
+	}
+
+	_, created, err := coll.EnsureInvertedIndex(ctx, &indexOptions)
+	if err != nil {
+		return false, fmt.Errorf("failed to create inverted index: %w", err)
+	}
+
+	return created, nil
+}
+
+// invertedIndexOptions decodes the options of an inverted index into the driver's
+// InvertedIndexOptions, so every option of the index definition can be used. Fields
+// may be given by name or as field objects.
+func invertedIndexOptions(name string, options map[string]interface{}) (arangodb.InvertedIndexOptions, error) {
+	var indexOptions arangodb.InvertedIndexOptions
+
+	definition := make(map[string]interface{}, len(options))
+	for key, value := range options {
+		definition[key] = value
+	}
+
+	if rawFields, exists := options["fields"]; exists {
+		fields, ok := rawFields.([]interface{})
+		if names, isStrings := rawFields.([]string); isStrings {
+			fields, ok = make([]interface{}, len(names)), true
+			for i, fieldName := range names {
+				fields[i] = fieldName
+			}
+		}
+		if !ok {
+			return indexOptions, fmt.Errorf("fields option not an array")
+		}
+		normalized := make([]interface{}, len(fields))
+		for i, field := range fields {
+			if fieldName, ok := field.(string); ok {
+				normalized[i] = map[string]interface{}{"name": fieldName}
+			} else {
+				normalized[i] = field
+			}
+		}
+		definition["fields"] = normalized
+	} else if includeAllFields, _ := options["includeAllFields"].(bool); !includeAllFields {
+		return indexOptions, fmt.Errorf("fields option missing and includeAllFields not set")
+	}
+
+	err := decodeOptions(definition, &indexOptions, "collection")
+	if err != nil {
+		return indexOptions, err
+	}
+
+	indexOptions.Name = name
+	return indexOptions, nil
+}
+
+// vectorParams are the parameters of a vector index.
+type vectorParams struct {
+	Metric             *string `json:"metric,omitempty"`
+	Dimension          *int    `json:"dimension,omitempty"`
+	NLists             *int    `json:"nLists,omitempty"`
+	DefaultNProbe      *int    `json:"defaultNProbe,omitempty"`
+	TrainingIterations *int    `json:"trainingIterations,omitempty"`
+	Factory            *string `json:"factory,omitempty"`
+}
+
+// vectorIndexRequest is the body of a request creating a vector index.
+type vectorIndexRequest struct {
+	Type         string       `json:"type"`
+	Name         string       `json:"name"`
+	Fields       []string     `json:"fields"`
+	Params       vectorParams `json:"params"`
+	InBackground *bool        `json:"inBackground,omitempty"`
+	Parallelism  *int         `json:"parallelism,omitempty"`
+}
+
+// createVectorIndex creates a vector index. The driver has no call for vector
+// indexes, so the request is sent over the client's connection.
+//
+//	{
+//		"type": "createVectorIndex",
+//		"name": "idx_products_embedding",
+//		"options": {
+//		  "collection": "products",
+//		  "fields": ["embedding"],
+//		  "params": {
+//		    "metric": "cosine",
+//		    "dimension": 768,
+//		    "nLists": 100
+//		  }
+//		}
+//	}
+func createVectorIndex(ctx context.Context, db arangodb.Database, client arangodb.Client, name string, options map[string]interface{}) (bool, error) {
+	request, err := vectorIndexOptions(name, options)
+	if err != nil {
+		return false, err
+	}
+
+	return postIndex(ctx, db, client, "vector", name, options, request)
+}
+
+func vectorIndexOptions(name string, options map[string]interface{}) (vectorIndexRequest, error) {
+	request := vectorIndexRequest{
+		Type: "vector",
+		Name: name,
+	}
+
+	fields, err := indexFields(options)
+	if err != nil {
+		return request, err
+	}
+	if len(fields) != 1 {
+		return request, fmt.Errorf("fields option must contain exactly one field")
+	}
+	request.Fields = fields
+
+	var indexOptions struct {
+		Params       *vectorParams `json:"params"`
+		InBackground *bool         `json:"inBackground"`
+		Parallelism  *int          `json:"parallelism"`
+	}
+	err = decodeOptions(options, &indexOptions, "collection", "fields")
+	if err != nil {
+		return request, err
+	}
+
+	params := indexOptions.Params
+	if params == nil {
+		return request, fmt.Errorf("params option missing or not an object")
+	}
+	if params.Dimension == nil || params.Metric == nil || params.NLists == nil {
+		return request, fmt.Errorf("params option must set dimension, metric and nLists")
+	}
+
+	request.Params = *params
+	request.InBackground = indexOptions.InBackground
+	request.Parallelism = indexOptions.Parallelism
+	return request, nil
+}
+
+// fulltextIndexRequest is the body of a request creating a fulltext index.
+type fulltextIndexRequest struct {
+	Type         string   `json:"type"`
+	Name         string   `json:"name"`
+	Fields       []string `json:"fields"`
+	MinLength    *int     `json:"minLength,omitempty"`
+	InBackground *bool    `json:"inBackground,omitempty"`
+}
+
+// createFulltextIndex creates a fulltext index. Fulltext indexes are deprecated in
+// ArangoDB in favor of inverted indexes and the driver has no call for them, so the
+// request is sent over the client's connection.
+//
+//	{
+//		"type": "createFulltextIndex",
+//		"name": "idx_posts_title",
+//		"options": {
+//		  "collection": "posts",
+//		  "fields": ["title"],
+//		  "minLength": 3
+//		}
+//	}
+func createFulltextIndex(ctx context.Context, db arangodb.Database, client arangodb.Client, name string, options map[string]interface{}) (bool, error) {
+	request, err := fulltextIndexOptions(name, options)
+	if err != nil {
+		return false, err
+	}
+
+	return postIndex(ctx, db, client, "fulltext", name, options, request)
+}
+
+func fulltextIndexOptions(name string, options map[string]interface{}) (fulltextIndexRequest, error) {
+	request := fulltextIndexRequest{
+		Type: "fulltext",
+		Name: name,
+	}
+
+	fields, err := indexFields(options)
+	if err != nil {
+		return request, err
+	}
+	if len(fields) != 1 {
+		return request, fmt.Errorf("fields option must contain exactly one field")
+	}
+	request.Fields = fields
+
+	var indexOptions struct {
+		MinLength    *int  `json:"minLength"`
+		InBackground *bool `json:"inBackground"`
+	}
+	err = decodeOptions(options, &indexOptions, "collection", "fields")
+	if err != nil {
+		return request, err
+	}
+	if indexOptions.MinLength != nil && *indexOptions.MinLength < 1 {
+		return request, fmt.Errorf("minLength option must be at least 1")
+	}
+
+	request.MinLength = indexOptions.MinLength
+	request.InBackground = indexOptions.InBackground
+	return request, nil
+}
+
+// clientIndex adapts a create function of an index type that is created through
+// the client to the signature of the other create*Index functions.
+func clientIndex(client arangodb.Client, create func(ctx context.Context, db arangodb.Database, client arangodb.Client, name string, options map[string]interface{}) (bool, error)) func(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (bool, error) {
+	return func(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (bool, error) {
+		return create(ctx, db, client, name, options)
+	}
+}
+
+// postIndex creates an index the driver has no call for by posting its definition
+// to the index API, and reports whether it was created.
+func postIndex(ctx context.Context, db arangodb.Database, client arangodb.Client, indexType string, name string, options map[string]interface{}, request interface{}) (bool, error) {
+	if client == nil {
+		return false, fmt.Errorf("creating %s index '%s' requires MigrationOptions.Client", indexType, name)
+	}
+
+	coll, err := indexCollection(ctx, db, options)
+	if err != nil {
+		return false, err
+	}
+
+	var response struct {
+		shared.ResponseStruct `json:",inline"`
+		IsNewlyCreated        bool `json:"isNewlyCreated"`
+	}
+
+	url := connection.NewUrl("_db", db.Name(), "_api", "index")
+	resp, err := connection.CallPost(ctx, client.Connection(), url, &response, request, connection.WithQuery("collection", coll.Name()))
+	if err != nil {
+		return false, fmt.Errorf("failed to create %s index: %w", indexType, err)
+	}
+
+	if resp.Code() != http.StatusOK && resp.Code() != http.StatusCreated {
+		return false, fmt.Errorf("failed to create %s index: %w", indexType, response.AsArangoErrorWithCode(resp.Code()))
+	}
+
+	return response.IsNewlyCreated, nil
+}
+
+func deleteIndex(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
+	collName, ok := options["collection"].(string)
+	if !ok {
+		return fmt.Errorf("collection name missing or not a string")
+	}
+
+	coll, err := db.GetCollection(ctx, collName, &arangodb.GetCollectionOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get collection '%s' for index deletion: %w", collName, err)
+	}
+
+	err = coll.DeleteIndex(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to remove index: %w", err)
+	}
+
+	return nil
+}
+
+// indexFields returns the fields option of an index operation.
+func indexFields(options map[string]interface{}) ([]string, error) {
+	fields, ok := getSlice[string](options, "fields")
+	if !ok {
+		return nil, fmt.Errorf("fields option missing or not a string array")
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fields option must not be empty")
+	}
+	return fields, nil
+}
+
+// setBoolOptions sets the driver option for each of the given keys that is present
+// in the operation options.
+func setBoolOptions(options map[string]interface{}, targets map[string]**bool) error {
+	for key, target := range targets {
+		value, exists := options[key]
+		if !exists {
+			continue
+		}
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("%s option not a boolean", key)
+		}
+		*target = &b
+	}
+	return nil
+}
+
+// stringSliceOption returns an optional string array option.
+func stringSliceOption(options map[string]interface{}, key string) ([]string, error) {
+	if _, exists := options[key]; !exists {
+		return nil, nil
+	}
+	values, ok := getSlice[string](options, key)
+	if !ok {
+		return nil, fmt.Errorf("%s option not a string array", key)
+	}
+	return values, nil
+}
+
+// decodeOptions decodes operation options into a driver options struct through JSON,
+// skipping the given keys. Unknown options are rejected so typos don't go unnoticed.
+func decodeOptions(options map[string]interface{}, target interface{}, skip ...string) error {
+	filtered := make(map[string]interface{}, len(options))
+	for key, value := range options {
+		filtered[key] = value
+	}
+	for _, key := range skip {
+		delete(filtered, key)
+	}
+
+	data, err := json.Marshal(filtered)
+	if err != nil {
+		return fmt.Errorf("failed to encode options: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(target)
+	if err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+
+	return nil
+}
+
+// decodeKnownOptions decodes operation options like decodeOptions, but ignores the
+// options unknownOptions reports instead of rejecting them. It is used by operations
+// that ignored unknown options before they supported every driver option, so
+// migrations that were already applied keep working.
+func decodeKnownOptions(options map[string]interface{}, target interface{}, skip ...string) error {
+	return decodeOptions(options, target, slices.Concat(skip, unknownOptions(options, target, skip...))...)
+}
+
+// unknownOptions returns the sorted keys of operation options that the driver options
+// struct target has no field for, skipping the given keys. Like encoding/json, keys
+// match field names case-insensitively.
+func unknownOptions(options map[string]interface{}, target interface{}, skip ...string) []string {
+	fields := jsonFieldNames(reflect.TypeOf(target))
+
+	var unknown []string
+	for key := range options {
+		if slices.Contains(skip, key) {
+			continue
+		}
+		if !slices.ContainsFunc(fields, func(field string) bool { return strings.EqualFold(field, key) }) {
+			unknown = append(unknown, key)
+		}
+	}
+	slices.Sort(unknown)
+	return unknown
+}
+
+// jsonFieldNames returns the JSON names of the fields of a struct type, including
+// the fields of embedded structs.
+func jsonFieldNames(t reflect.Type) []string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var names []string
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || (field.Anonymous && field.Tag.Get("json") == "") {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -139,7 +139,7 @@ func TestOperationsIntegration(t *testing.T) {
 		require.NoError(t, err)
 
 		// Create a persistent index
-		err = createPersistentIndex(ctx, db, "idx_test_field", map[string]interface{}{
+		_, err = createPersistentIndex(ctx, db, "idx_test_field", map[string]interface{}{
 			"collection": "test_collection",
 			"fields":     []string{"field1"},
 			"unique":     true,
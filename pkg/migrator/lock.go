@@ -0,0 +1,302 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/arangodb/go-driver/v2/arangodb/shared"
+)
+
+const (
+	// migrationLockKey is the key of the single lock document in the lock collection.
+	migrationLockKey = "migration_lock"
+
+	// defaultLockTTL is used when MigrationOptions.LockTTL is not set.
+	defaultLockTTL = 30 * time.Second
+
+	// minLockTTL is the shortest MigrationOptions.LockTTL accepted. The lock document
+	// stores the TTL in milliseconds and the heartbeat runs three times per TTL.
+	minLockTTL = time.Second
+
+	// defaultLockWaitTimeout is used when MigrationOptions.LockWaitTimeout is not set.
+	defaultLockWaitTimeout = 5 * time.Minute
+
+	// lockRetryInterval is how often a waiting process retries to acquire the lock.
+	lockRetryInterval = time.Second
+)
+
+// The lock is taken when the document doesn't exist, has expired, or is already
+// held by the same owner. Expiry is evaluated with the server clock so replicas
+// with skewed clocks agree on when a lock has expired.
+const acquireLockQuery = `UPSERT { _key: @key }
+INSERT { _key: @key, owner: @owner, acquiredAt: DATE_NOW(), heartbeatAt: DATE_NOW(), expiresAt: DATE_NOW() + @ttl }
+UPDATE (OLD.expiresAt < DATE_NOW() || OLD.owner == @owner)
+	? { owner: @owner, acquiredAt: DATE_NOW(), heartbeatAt: DATE_NOW(), expiresAt: DATE_NOW() + @ttl }
+	: {}
+IN @@collection
+RETURN NEW`
+
+const heartbeatLockQuery = `FOR l IN @@collection
+FILTER l._key == @key AND l.owner == @owner
+UPDATE l WITH { heartbeatAt: DATE_NOW(), expiresAt: DATE_NOW() + @ttl } IN @@collection
+RETURN NEW`
+
+const releaseLockQuery = `FOR l IN @@collection
+FILTER l._key == @key AND l.owner == @owner
+REMOVE l IN @@collection`
+
+// migrationLockDocument is the lock document stored in the lock collection.
+// Timestamps are milliseconds since the epoch as reported by the server.
+type migrationLockDocument struct {
+	Key         string `json:"_key"`
+	Owner       string `json:"owner"`
+	AcquiredAt  int64  `json:"acquiredAt"`
+	HeartbeatAt int64  `json:"heartbeatAt"`
+	ExpiresAt   int64  `json:"expiresAt"`
+}
+
+// migrationLock is a held migration lock. Its context is cancelled if the lock
+// is lost, so work done under the lock stops instead of racing another owner.
+type migrationLock struct {
+	db         arangodb.Database
+	collection string
+	owner      string
+	ttl        time.Duration
+	log        *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// lockTTL returns the TTL of the migration lock, which must be at least minLockTTL.
+func lockTTL(options MigrationOptions) (time.Duration, error) {
+	if options.LockTTL <= 0 {
+		return defaultLockTTL, nil
+	}
+	if options.LockTTL < minLockTTL {
+		return 0, fmt.Errorf("lock TTL %s is too short, it must be at least %s", options.LockTTL, minLockTTL)
+	}
+	return options.LockTTL, nil
+}
+
+// acquireMigrationLock waits until the migration lock is acquired or the wait
+// timeout expires, then keeps it alive with a heartbeat until released.
+func acquireMigrationLock(ctx context.Context, db arangodb.Database, options MigrationOptions) (*migrationLock, error) {
+	collection := lockCollectionName(options)
+	ttl, err := lockTTL(options)
+	if err != nil {
+		return nil, err
+	}
+	waitTimeout := options.LockWaitTimeout
+	if waitTimeout <= 0 {
+		waitTimeout = defaultLockWaitTimeout
+	}
+	owner := options.LockOwner
+	if owner == "" {
+		owner = defaultLockOwner()
+	}
+	log := options.logger()
+
+	err = ensureLockCollection(ctx, db, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	deadline := start.Add(waitTimeout)
+	for {
+		holder, err := tryAcquireLock(ctx, db, collection, owner, ttl)
+		if err != nil {
+			return nil, err
+		}
+
+		if holder.Owner == owner {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out after %s waiting for migration lock held by %s", waitTimeout, holder.Owner)
+		}
+
+		log.Info("migration lock is held by another process, waiting", "holder", holder.Owner)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("cancelled while waiting for migration lock: %w", ctx.Err())
+		case <-time.After(lockRetryInterval):
+		}
+	}
+
+	log.Info("acquired migration lock", "owner", owner, "duration", time.Since(start))
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	lock := &migrationLock{
+		db:         db,
+		collection: collection,
+		owner:      owner,
+		ttl:        ttl,
+		log:        log,
+		ctx:        lockCtx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+
+	go lock.heartbeat()
+	return lock, nil
+}
+
+// tryAcquireLock makes a single attempt to acquire the lock and returns the
+// lock document as it is after the attempt.
+func tryAcquireLock(ctx context.Context, db arangodb.Database, collection string, owner string, ttl time.Duration) (migrationLockDocument, error) {
+	var holder migrationLockDocument
+
+	cursor, err := db.Query(ctx, acquireLockQuery, &arangodb.QueryOptions{
+		BindVars: map[string]interface{}{
+			"@collection": collection,
+			"key":         migrationLockKey,
+			"owner":       owner,
+			"ttl":         ttl.Milliseconds(),
+		},
+	})
+	if err != nil {
+		// Concurrent attempts to create or take over the lock document conflict
+		// with each other; the loser simply retries.
+		if shared.IsConflict(err) {
+			return holder, nil
+		}
+		return holder, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer cursor.Close()
+
+	_, err = cursor.ReadDocument(ctx, &holder)
+	if err != nil {
+		return holder, fmt.Errorf("failed to read migration lock: %w", err)
+	}
+
+	return holder, nil
+}
+
+// heartbeat extends the lock until it is released. If the lock cannot be
+// extended because another owner took it over, or no heartbeat succeeded for a
+// whole TTL so the lock may have expired, the lock context is cancelled.
+func (l *migrationLock) heartbeat() {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	lastHeartbeat := time.Now()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+		}
+
+		cursor, err := l.db.Query(l.ctx, heartbeatLockQuery, &arangodb.QueryOptions{
+			BindVars: map[string]interface{}{
+				"@collection": l.collection,
+				"key":         migrationLockKey,
+				"owner":       l.owner,
+				"ttl":         l.ttl.Milliseconds(),
+			},
+		})
+		if err != nil {
+			// A transient failure is retried on the next tick; the lock only
+			// expires if heartbeats keep failing for the whole TTL, after which
+			// another process may take it over.
+			if time.Since(lastHeartbeat) >= l.ttl {
+				l.log.Error("failed to extend migration lock before it expired, aborting", "owner", l.owner, "ttl", l.ttl, "error", err)
+				l.cancel()
+				return
+			}
+			l.log.Warn("failed to extend migration lock", "owner", l.owner, "error", err)
+			continue
+		}
+
+		stillHeld := cursor.HasMore()
+		cursor.Close()
+
+		if !stillHeld {
+			l.log.Error("migration lock was lost, aborting", "owner", l.owner)
+			l.cancel()
+			return
+		}
+		lastHeartbeat = time.Now()
+	}
+}
+
+// release stops the heartbeat and removes the lock document. It is safe to
+// call more than once.
+func (l *migrationLock) release() {
+	l.once.Do(func() {
+		close(l.done)
+		defer l.cancel()
+
+		// Release even if the caller's context was cancelled, so other
+		// processes don't have to wait for the lock to expire.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(l.ctx), l.ttl)
+		defer cancel()
+
+		cursor, err := l.db.Query(ctx, releaseLockQuery, &arangodb.QueryOptions{
+			BindVars: map[string]interface{}{
+				"@collection": l.collection,
+				"key":         migrationLockKey,
+				"owner":       l.owner,
+			},
+		})
+		if err != nil {
+			l.log.Warn("failed to release migration lock, it will expire after its TTL", "owner", l.owner, "ttl", l.ttl, "error", err)
+			return
+		}
+		cursor.Close()
+
+		l.log.Info("released migration lock", "owner", l.owner)
+	})
+}
+
+// ensureLockCollection creates the lock collection if it doesn't exist. Several
+// processes may race to create it, so a conflict means another one won.
+func ensureLockCollection(ctx context.Context, db arangodb.Database, name string) error {
+	exists, err := db.CollectionExists(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to check if lock collection exists: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	_, err = db.CreateCollection(ctx, name, &arangodb.CreateCollectionProperties{
+		Type: arangodb.CollectionTypeDocument,
+	})
+	if err != nil && !shared.IsConflict(err) {
+		return fmt.Errorf("failed to create lock collection in specified db: %w", err)
+	}
+
+	return nil
+}
+
+// lockCollectionName returns the configured lock collection or the default
+// derived from the migration collection.
+func lockCollectionName(options MigrationOptions) string {
+	if options.LockCollection != "" {
+		return options.LockCollection
+	}
+	return options.MigrationCollection + "_lock"
+}
+
+// defaultLockOwner identifies this process in the lock document.
+func defaultLockOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return hostname + ":" + strconv.Itoa(os.Getpid()) + ":" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
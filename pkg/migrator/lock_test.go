@@ -0,0 +1,216 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FramnkRulez/go-arangodb-migrator/pkg/migrator/testutil"
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLockCollectionName tests the default and configured lock collection names
+func TestLockCollectionName(t *testing.T) {
+	assert.Equal(t, "migrations_lock", lockCollectionName(MigrationOptions{
+		MigrationCollection: "migrations",
+	}))
+
+	assert.Equal(t, "custom_lock", lockCollectionName(MigrationOptions{
+		MigrationCollection: "migrations",
+		LockCollection:      "custom_lock",
+	}))
+}
+
+// TestLockTTL tests the default and minimum lock TTL
+func TestLockTTL(t *testing.T) {
+	ttl, err := lockTTL(MigrationOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultLockTTL, ttl)
+
+	ttl, err = lockTTL(MigrationOptions{LockTTL: 2 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, ttl)
+
+	_, err = lockTTL(MigrationOptions{LockTTL: 2 * time.Nanosecond})
+	assert.EqualError(t, err, "lock TTL 2ns is too short, it must be at least 1s")
+}
+
+// unreachableDatabase fails every query, like a database the process lost its
+// connection to.
+type unreachableDatabase struct {
+	arangodb.Database
+}
+
+func (unreachableDatabase) Query(ctx context.Context, query string, opts *arangodb.QueryOptions) (arangodb.Cursor, error) {
+	return nil, errors.New("connection refused")
+}
+
+// TestLockHeartbeatFailing tests that the lock context is cancelled once the
+// heartbeat has failed for longer than the TTL
+func TestLockHeartbeatFailing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lock := &migrationLock{
+		db:         unreachableDatabase{},
+		collection: "migrations_lock",
+		owner:      "test-owner",
+		ttl:        300 * time.Millisecond,
+		log:        MigrationOptions{}.logger(),
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	defer close(lock.done)
+
+	start := time.Now()
+	go lock.heartbeat()
+
+	select {
+	case <-lock.ctx.Done():
+		assert.GreaterOrEqual(t, time.Since(start), lock.ttl, "Lock should be kept until the TTL has passed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Lock context should be cancelled after the heartbeat failed for the whole TTL")
+	}
+}
+
+func TestMigrateArangoDatabaseConcurrently(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_concurrent_migrations")
+
+	tempDir := t.TempDir()
+	for i := 1; i <= 3; i++ {
+		migration := fmt.Sprintf(`{
+			"description": "Create collection %d",
+			"up": [
+				{
+					"type": "createCollection",
+					"name": "collection_%d",
+					"options": {
+						"type": "document"
+					}
+				}
+			]
+		}`, i, i)
+
+		err := os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("%06d_collection.json", i)), []byte(migration), 0644)
+		require.NoError(t, err)
+	}
+
+	// Simulate several replicas starting at the same time
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = MigrateArangoDatabase(ctx, db, MigrationOptions{
+				MigrationFolder:     tempDir,
+				MigrationCollection: "migrations",
+				LockOwner:           fmt.Sprintf("replica-%d", i),
+				LockWaitTimeout:     time.Minute,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		assert.NoError(t, err, "replica %d should not fail", i)
+	}
+
+	migrationsColl, err := db.GetCollection(ctx, "migrations", nil)
+	require.NoError(t, err)
+
+	count, err := migrationsColl.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count, "Each migration should be recorded exactly once")
+
+	// The lock should be released once every replica is done
+	lockColl, err := db.GetCollection(ctx, "migrations_lock", nil)
+	require.NoError(t, err)
+
+	exists, err := lockColl.DocumentExists(ctx, migrationLockKey)
+	require.NoError(t, err)
+	assert.False(t, exists, "Lock should have been released")
+}
+
+func TestMigrateArangoDatabaseLockWaitTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_lock_timeout")
+
+	tempDir := t.TempDir()
+	options := MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+		LockWaitTimeout:     2 * time.Second,
+	}
+
+	// Another process holds the lock
+	held, err := acquireMigrationLock(ctx, db, MigrationOptions{
+		MigrationCollection: "migrations",
+		LockOwner:           "other-process",
+	})
+	require.NoError(t, err)
+
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Contains(t, err.Error(), "other-process")
+
+	// Once released, migrations can run again
+	held.release()
+
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+}
+
+func TestMigrateArangoDatabaseLockExpired(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_lock_expired")
+
+	// Simulate a process that crashed while holding the lock
+	err := ensureLockCollection(ctx, db, "migrations_lock")
+	require.NoError(t, err)
+
+	lockColl, err := db.GetCollection(ctx, "migrations_lock", nil)
+	require.NoError(t, err)
+
+	_, err = lockColl.CreateDocument(ctx, migrationLockDocument{
+		Key:        migrationLockKey,
+		Owner:      "crashed-process",
+		AcquiredAt: 0,
+		ExpiresAt:  1,
+	})
+	require.NoError(t, err)
+
+	// The expired lock is taken over without waiting
+	err = MigrateArangoDatabase(ctx, db, MigrationOptions{
+		MigrationFolder:     t.TempDir(),
+		MigrationCollection: "migrations",
+		LockWaitTimeout:     2 * time.Second,
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,108 @@
+package migrator
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultLogger writes to the standard logrus logger, which is what the package
+// logged to before MigrationOptions.Logger was added.
+var defaultLogger = slog.New(&logrusHandler{logger: logrus.StandardLogger()})
+
+// logger returns the logger migrations are logged with.
+func (options MigrationOptions) logger() *slog.Logger {
+	if options.Logger != nil {
+		return options.Logger
+	}
+	return defaultLogger
+}
+
+// logrusHandler is a slog.Handler that writes records to a logrus logger, with the
+// attributes as logrus fields.
+type logrusHandler struct {
+	logger *logrus.Logger
+	attrs  []slog.Attr
+	group  string
+}
+
+func (h *logrusHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.logger.IsLevelEnabled(logrusLevel(level))
+}
+
+func (h *logrusHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := make(logrus.Fields, len(h.attrs)+record.NumAttrs())
+	for _, attr := range h.attrs {
+		addLogrusField(fields, "", attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addLogrusField(fields, h.group, attr)
+		return true
+	})
+
+	h.logger.WithContext(ctx).WithTime(record.Time).WithFields(fields).Log(logrusLevel(record.Level), record.Message)
+	return nil
+}
+
+func (h *logrusHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handler := *h
+	handler.attrs = make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	handler.attrs = append(handler.attrs, h.attrs...)
+	for _, attr := range attrs {
+		if h.group != "" {
+			attr.Key = h.group + "." + attr.Key
+		}
+		handler.attrs = append(handler.attrs, attr)
+	}
+	return &handler
+}
+
+func (h *logrusHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	handler := *h
+	if h.group != "" {
+		name = h.group + "." + name
+	}
+	handler.group = name
+	return &handler
+}
+
+// addLogrusField adds an attribute to fields, flattening groups into dotted keys.
+func addLogrusField(fields logrus.Fields, group string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	key := attr.Key
+	if group != "" && key != "" {
+		key = group + "." + key
+	}
+
+	if value.Kind() == slog.KindGroup {
+		if key == "" {
+			key = group
+		}
+		for _, groupAttr := range value.Group() {
+			addLogrusField(fields, key, groupAttr)
+		}
+		return
+	}
+
+	if key != "" {
+		fields[key] = value.Any()
+	}
+}
+
+// logrusLevel maps a slog level to the closest logrus level.
+func logrusLevel(level slog.Level) logrus.Level {
+	switch {
+	case level >= slog.LevelError:
+		return logrus.ErrorLevel
+	case level >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case level >= slog.LevelInfo:
+		return logrus.InfoLevel
+	default:
+		return logrus.DebugLevel
+	}
+}
@@ -0,0 +1,65 @@
+package migrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrationOptionsLogger tests that migrations are logged to the configured logger
+func TestMigrationOptionsLogger(t *testing.T) {
+	assert.Same(t, defaultLogger, MigrationOptions{}.logger())
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	assert.Same(t, logger, MigrationOptions{Logger: logger}.logger())
+
+	logPlan(logger, &MigrationPlan{Migrations: []PlannedMigration{{
+		MigrationNumber: "000001_users",
+		Description:     "Create users",
+		Operations: []PlannedOperation{
+			{Type: "createCollection", Name: "users", Outcome: PlanOutcomeError, Reason: "collection 'users' already exists"},
+		},
+	}}})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, "000001_users", record["migration"])
+	assert.Equal(t, "createCollection", record["operation"])
+	assert.Equal(t, "users", record["name"])
+	assert.Equal(t, "collection 'users' already exists", record["reason"])
+	assert.Equal(t, true, record["dryRun"])
+}
+
+// TestMigrationOptionsLogrusHandler tests the default logger writing to logrus
+func TestMigrationOptionsLogrusHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(&buf)
+	logrusLogger.SetFormatter(&logrus.JSONFormatter{})
+	logrusLogger.SetLevel(logrus.InfoLevel)
+
+	logger := slog.New(&logrusHandler{logger: logrusLogger})
+	logger.Debug("not logged", "migration", "000001_users")
+	assert.Empty(t, buf.String())
+
+	logger.With("migration", "000001_users").WithGroup("operation").Warn("operation failed", "type", "createCollection", slog.Group("result", "name", "users"))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "warning", entry["level"])
+	assert.Equal(t, "operation failed", entry["msg"])
+	assert.Equal(t, "000001_users", entry["migration"])
+	assert.Equal(t, "createCollection", entry["operation.type"])
+	assert.Equal(t, "users", entry["operation.result.name"])
+}
@@ -8,27 +8,67 @@
 //   - Supports rollback on failure
 //   - Verifies file integrity using SHA256 hashes
 //   - Tracks applied migrations in a collection
+//   - Prevents concurrent runs against the same database with a lock document
 //   - Supports various ArangoDB operations (collections, indexes, graphs, documents)
 //
 // # Migration Files
 //
-// Migration files should be JSON files with numeric prefixes (e.g., "000001.json", "000002.json").
-// Each file contains a description and operations to perform.
+// Migration files should be JSON or YAML files with numeric prefixes (e.g., "000001.json", "000002.yaml").
+// Each file contains a description and operations to perform. An optional "down" list
+// describes how to undo the migration and is used by RollbackArangoDatabase.
+//
+// A migration that only changes documents can set "transactional" to run its
+// operations in a single stream transaction that is committed or aborted as a whole.
+//
+// Migrations that can't be expressed as operations can be written in Go and added
+// with Register. They are applied in order with the migration files.
+//
+// Migration files can also be read from an fs.FS, such as an embed.FS, by setting
+// MigrationOptions.MigrationFS.
+//
+// Every operation type is implemented by an OperationHandler. Custom operation types
+// can be added by registering handlers on a Migrator created with New. A Migrator
+// also calls Hooks before and after every migration and operation.
 //
 // # Supported Operations
 //
 //   - createCollection: Create document or edge collections
+//   - modifyCollection: Change mutable collection properties
+//   - setCollectionSchema: Set the document validation schema of a collection
+//   - renameCollection: Rename collections
+//   - truncateCollection: Remove all documents of a collection, optionally keeping a backup
 //   - deleteCollection: Remove collections
 //   - createPersistentIndex: Create persistent indexes
 //   - createGeoIndex: Create geo indexes
+//   - createTTLIndex: Create TTL indexes that expire documents
+//   - createInvertedIndex: Create inverted indexes for search
+//   - createMDIIndex: Create multi-dimensional (mdi and mdi-prefixed) indexes
+//   - createVectorIndex: Create vector indexes for similarity search
+//   - createFulltextIndex: Create (deprecated) fulltext indexes
 //   - deleteIndex: Remove indexes
 //   - createGraph: Create named graphs
 //   - deleteGraph: Remove graphs
 //   - addEdgeDefinition: Add edge definitions to graphs
 //   - deleteEdgeDefinition: Remove edge definitions
+//   - replaceEdgeDefinition: Change the vertex collections of edge definitions
+//   - addVertexCollection: Add orphan collections to graphs
+//   - removeVertexCollection: Remove orphan collections from graphs
 //   - addDocument: Add documents to collections
 //   - updateDocument: Update existing documents
 //   - deleteDocument: Remove documents
+//   - importDocuments: Create many documents from an inline array or a JSONL or CSV file
+//   - executeAQL: Run an AQL query, e.g. to transform documents in bulk
+//   - createDatabase: Create databases, e.g. for another service
+//   - createUser: Create users, e.g. service accounts
+//   - grantDatabaseAccess: Set the access level of a user to a database
+//   - grantCollectionAccess: Set the access level of a user to a collection
+//   - revokeAccess: Remove the access level of a user to a database or collection
+//   - createAnalyzer: Create ArangoSearch analyzers
+//   - deleteAnalyzer: Remove analyzers
+//   - createArangoSearchView: Create arangosearch views
+//   - createSearchAliasView: Create search-alias views over inverted indexes
+//   - updateViewProperties: Change view properties and links
+//   - deleteView: Remove views
 //
 // # Example Usage
 //
@@ -47,6 +87,24 @@
 //		Force:               false, // Set to true to bypass file modification checks
 //	})
 //
+//	// List applied and pending migrations
+//	statuses, err := migrator.Status(ctx, db, migrator.MigrationOptions{
+//		MigrationFolder:     "./migrations",
+//		MigrationCollection: "migrations",
+//	})
+//
+//	// Check what would be applied without changing the database
+//	plan, err := migrator.Plan(ctx, db, migrator.MigrationOptions{
+//		MigrationFolder:     "./migrations",
+//		MigrationCollection: "migrations",
+//	})
+//
+//	// Roll back everything applied after migration 000002
+//	err = migrator.RollbackArangoDatabase(ctx, db, migrator.MigrationOptions{
+//		MigrationFolder:     "./migrations",
+//		MigrationCollection: "migrations",
+//	}, "000002")
+//
 // # CLI Tool
 //
 // A command-line tool is also available for running migrations:
@@ -68,24 +126,39 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"os"
-	"path/filepath"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/arangodb/go-driver/v2/arangodb"
 	"github.com/arangodb/go-driver/v2/arangodb/shared"
-	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 )
 
 // MigrationOptions configures the migration process.
 type MigrationOptions struct {
 	// MigrationFolder is the path to the directory containing migration files.
-	// Migration files should be named with a numeric prefix (e.g., "000001.json").
+	// Migration files should be named with a numeric prefix (e.g., "000001.json" or
+	// "000001.yaml"). It may be left empty if all migrations are registered with
+	// Register.
+	//
+	// If MigrationFS is set, MigrationFolder is a directory within MigrationFS and
+	// defaults to its root.
 	MigrationFolder string
 
+	// MigrationFS is the file system migration files are read and hashed from, such
+	// as an embed.FS compiled into the binary. If nil, MigrationFolder is read from
+	// the operating system's file system.
+	MigrationFS fs.FS
+
 	// MigrationCollection is the name of the collection that tracks applied migrations.
 	// This collection will be created automatically if it doesn't exist.
 	MigrationCollection string
@@ -98,6 +171,58 @@ type MigrationOptions struct {
 	// if any migration fails. This ensures database consistency by rolling back
 	// to the state before the migration batch started.
 	AutoRollback bool
+
+	// CommitEachMigration records each migration in the migration collection as soon
+	// as its operations succeed, instead of recording the whole batch at the end.
+	// If a later migration fails, the migrations before it stay recorded and only the
+	// failed migration's operations are rolled back, so the next run resumes from the
+	// failed migration. Combined with AutoRollback, the records written during the
+	// batch are removed again when the batch is rolled back.
+	CommitEachMigration bool
+
+	// LockCollection is the name of the collection holding the lock that prevents
+	// several processes from migrating the same database at once. Defaults to
+	// MigrationCollection with a "_lock" suffix. It is created automatically.
+	LockCollection string
+
+	// LockTTL is how long the lock stays valid without a heartbeat. A process that
+	// dies while holding the lock blocks others for at most this long. Defaults to 30s;
+	// shorter than 1s is rejected.
+	LockTTL time.Duration
+
+	// LockWaitTimeout is how long to wait for another process to release the lock
+	// before giving up with an error. Defaults to 5 minutes.
+	LockWaitTimeout time.Duration
+
+	// LockOwner identifies this process in the lock document. Defaults to the
+	// hostname and process ID.
+	LockOwner string
+
+	// TargetVersion stops the migration after the migration with this numeric
+	// prefix, like "000003" or "3". Pending migrations with a greater prefix are
+	// left pending. An error is returned if no migration has the prefix. If empty,
+	// every pending migration is applied.
+	TargetVersion string
+
+	// Steps limits the migration to the next Steps pending migrations. If 0, every
+	// pending migration (up to TargetVersion) is applied.
+	Steps int
+
+	// DryRun makes MigrateArangoDatabase compute and log a plan of the pending
+	// migrations (see Plan) instead of applying them. The database is not modified,
+	// and an error is returned if any planned operation is expected to fail.
+	DryRun bool
+
+	// Client is the client the database was opened with. It is only needed by
+	// operations that call server APIs the database doesn't expose: renameCollection,
+	// createDatabase, createVectorIndex, createFulltextIndex and the user and access
+	// operations.
+	Client arangodb.Client
+
+	// Logger receives the log records of migrations, with the migration number,
+	// operation type, resource name and duration as attributes where they apply.
+	// Defaults to a logger writing to the standard logrus logger.
+	Logger *slog.Logger
 }
 
 // Operation represents a single migration operation.
@@ -120,8 +245,15 @@ type Migration struct {
 	// Up contains the operations to apply when migrating forward.
 	Up []Operation `json:"up"`
 
-	// Down contains the operations to apply when rolling back (currently not implemented).
+	// Down contains the operations to apply when rolling the migration back
+	// with RollbackArangoDatabase. Operations are executed in the order listed.
 	Down []Operation `json:"down"`
+
+	// Transactional runs the operations of the migration in a single ArangoDB stream
+	// transaction, so they are committed together or not at all. Only addDocument,
+	// updateDocument and deleteDocument operations are allowed in a transactional
+	// migration. The down list is run in a transaction as well.
+	Transactional bool `json:"transactional,omitempty"`
 }
 
 // OperationResult tracks the result of a single operation for potential rollback.
@@ -163,19 +295,26 @@ type AppliedMigration struct {
 }
 
 // MigrateArangoDatabase applies all pending migrations to the specified database.
-// Migrations are applied in order based on their numeric filename prefix.
+// Migrations are applied in order based on their numeric filename prefix, together
+// with any Go migrations added with Register.
 // If any migration fails, the behavior depends on the AutoRollback option:
 //   - With AutoRollback=true: All migrations in the current batch are rolled back
 //   - With AutoRollback=false: Only operations from the failed migration are rolled back
 //
+// By default migrations are recorded as applied only once the whole batch succeeds.
+// With CommitEachMigration=true each migration is recorded as soon as it succeeds,
+// so a failed batch can be resumed from the migration that failed.
+//
 // The function performs the following steps:
-//  1. Creates the migration collection if it doesn't exist
-//  2. Reads all .json files from the migration folder
-//  3. Sorts migrations by numeric filename prefix
-//  4. Applies migrations that haven't been applied yet
-//  5. Verifies file integrity using SHA256 hashes (unless Force is true)
-//  6. Tracks operation results for potential rollback
-//  7. Rolls back on failure based on AutoRollback setting
+//  1. Acquires the migration lock, waiting for other processes to finish
+//  2. Creates the migration collection if it doesn't exist
+//  3. Reads all .json, .yaml and .yml files from the migration folder
+//  4. Sorts migrations by numeric filename prefix
+//  5. Applies migrations that haven't been applied yet
+//  6. Verifies file integrity using SHA256 hashes (unless Force is true)
+//  7. Tracks operation results for potential rollback
+//  8. Rolls back on failure based on AutoRollback setting
+//  9. Releases the migration lock
 //
 // # Parameters
 //
@@ -186,7 +325,8 @@ type AppliedMigration struct {
 // # Returns
 //
 // Returns an error if any migration fails. On failure, operations are rolled back
-// according to the AutoRollback setting.
+// according to the AutoRollback setting. With DryRun=true, returns an error if any
+// planned operation is expected to fail.
 //
 // # Examples
 //
@@ -213,116 +353,377 @@ type AppliedMigration struct {
 //		Force:               true, // Bypass file modification checks
 //	})
 //
+// With dry run (nothing is applied, the plan is logged):
+//
+//	err := migrator.MigrateArangoDatabase(ctx, db, migrator.MigrationOptions{
+//		MigrationFolder:     "./migrations",
+//		MigrationCollection: "migrations",
+//		DryRun:              true, // Check pending migrations against the database
+//	})
+//
 // PendingMigration represents a migration that needs to be applied.
 type PendingMigration struct {
 	MigrationNumber string
 	Migration       *Migration
 	Hash            string
-	FilePath        string
+
+	// FilePath is the path of the migration file within the migration folder, or
+	// within MigrationFS if it is set. It is empty for Go migrations.
+	FilePath string
 }
 
 func collectPendingMigrations(ctx context.Context, db arangodb.Database, options MigrationOptions) ([]PendingMigration, arangodb.Collection, error) {
-	var migrationColl arangodb.Collection
-	migrationColl, err := db.GetCollection(ctx, options.MigrationCollection, &arangodb.GetCollectionOptions{
-		SkipExistCheck: false,
-	})
+	migrationColl, err := getMigrationCollection(ctx, db, options.MigrationCollection)
 	if err != nil {
-		if shared.IsNotFound(err) {
-			migrationColl, err = db.CreateCollection(ctx, options.MigrationCollection, &arangodb.CreateCollectionProperties{
-				Type: arangodb.CollectionTypeDocument,
-			})
-			if err != nil {
-				return nil, nil, fmt.Errorf("failed to create migration collection in specified db: %v", err)
-			}
-		} else {
-			return nil, nil, err
-		}
+		return nil, nil, err
 	}
 
-	// Get all migrations from the migration folder
-	migrations, err := os.ReadDir(options.MigrationFolder)
+	pendingMigrations, err := findPendingMigrations(ctx, migrationColl, options)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	return pendingMigrations, migrationColl, nil
+}
+
+// findPendingMigrations returns the migrations in the migration folder that are not
+// recorded in migrationColl, up to the target version and number of steps of options.
+// A nil migrationColl means nothing has been applied yet.
+func findPendingMigrations(ctx context.Context, migrationColl arangodb.Collection, options MigrationOptions) ([]PendingMigration, error) {
+	targetVersion := -1
+	if options.TargetVersion != "" {
+		version, err := migrationVersion(options.TargetVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target version: %w", err)
+		}
+		targetVersion = version
+	}
+
+	if options.Steps < 0 {
+		return nil, fmt.Errorf("steps must not be negative: %d", options.Steps)
+	}
+
+	// Get all migrations from the migration folder and the registered Go migrations
+	migrationFiles, err := listMigrations(options)
+	if err != nil {
+		return nil, err
+	}
+
 	var pendingMigrations []PendingMigration
+	targetFound := false
+
+	for _, migrationFile := range migrationFiles {
+		migrationNumber := migrationFile.MigrationNumber
+		fullpath := migrationFile.Path
 
-	for _, migration := range migrations {
-		if strings.HasSuffix(migration.Name(), ".json") {
-			migrationNumber := strings.TrimSuffix(migration.Name(), ".json")
-			fullpath := filepath.Join(options.MigrationFolder, migration.Name())
+		version, err := migrationVersion(migrationNumber)
+		if err != nil {
+			return nil, err
+		}
+		if version == targetVersion {
+			targetFound = true
+		}
 
-			hash, err := getFileSHA256(fullpath)
+		// Go migrations have no file to hash
+		var hash string
+		if migrationFile.GoMigration == nil {
+			hash, err = getMigrationSHA256(migrationFile.FS, fullpath)
 			if err != nil {
-				return nil, nil, fmt.Errorf("failed to compute hash for migration file: %v", err)
+				return nil, fmt.Errorf("failed to compute hash for migration file: %w", err)
 			}
+		}
 
+		if migrationColl != nil {
 			var appliedMigration *AppliedMigration
 			_, err = migrationColl.ReadDocument(ctx, migrationNumber, &appliedMigration)
 			if err != nil {
 				if !shared.IsNotFound(err) {
-					return nil, nil, fmt.Errorf("failed to read applied migration: %v", err)
+					return nil, fmt.Errorf("failed to read applied migration: %w", err)
 				}
 			}
 
 			if appliedMigration != nil {
 				if appliedMigration.Sha256 != hash {
 					if options.Force {
-						logrus.Warnf("migration file %s has been modified since last applied, but continuing due to force flag", migrationNumber)
+						options.logger().Warn("migration file has been modified since last applied, but continuing due to force flag", "migration", migrationNumber)
 					} else {
-						return nil, nil, fmt.Errorf("migration file has been modified since last applied: %s (use --force to override)", migrationNumber)
+						return nil, fmt.Errorf("%w: %s (use --force to override)", ErrChecksumMismatch, migrationNumber)
 					}
 				}
 			}
 
 			exists, err := migrationColl.DocumentExists(ctx, migrationNumber)
 			if err != nil {
-				return nil, nil, fmt.Errorf("failed to check if migration exists: %v", err)
+				return nil, fmt.Errorf("failed to check if migration exists: %w", err)
 			}
 
 			if exists {
-				logrus.Infof("migration %s already applied, skipping...", migrationNumber)
+				options.logger().Info("migration already applied, skipping", "migration", migrationNumber)
 				continue
 			}
+		}
+
+		// Migrations after the target version or the number of steps stay pending
+		if targetVersion >= 0 && version > targetVersion {
+			continue
+		}
+		if options.Steps > 0 && len(pendingMigrations) == options.Steps {
+			continue
+		}
 
-			// Read the migration file
-			migrationFile, err := os.ReadFile(filepath.Join(options.MigrationFolder, migration.Name()))
+		var migrationData *Migration
+		if migrationFile.GoMigration != nil {
+			migrationData = migrationFile.GoMigration.migration()
+		} else {
+			migrationData, err = readPendingMigrationFile(migrationNumber, migrationFile.FS, fullpath)
 			if err != nil {
-				return nil, nil, err
+				return nil, err
 			}
+		}
+
+		pendingMigrations = append(pendingMigrations, PendingMigration{
+			MigrationNumber: migrationNumber,
+			Migration:       migrationData,
+			Hash:            hash,
+			FilePath:        fullpath,
+		})
+	}
+
+	if targetVersion >= 0 && !targetFound {
+		return nil, fmt.Errorf("target version %s does not match any migration", options.TargetVersion)
+	}
+
+	return pendingMigrations, nil
+}
+
+// readPendingMigrationFile reads a migration file that is about to be applied and
+// validates its structure.
+func readPendingMigrationFile(migrationNumber string, fsys fs.FS, path string) (*Migration, error) {
+	migrationData, err := readMigrationFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate migration structure
+	if len(migrationData.Up) == 0 {
+		return nil, fmt.Errorf("migration file %s does not include a valid 'up' list of migrations to apply", migrationNumber)
+	}
+
+	err = checkFileOperations(migrationNumber, migrationData.Up)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrationData, nil
+}
+
+// migrationFile is a migration file found in the migration folder.
+type migrationFile struct {
+	// MigrationNumber is the file name without extension (e.g., "000001_initial_schema").
+	MigrationNumber string
+
+	// FS is the file system the migration file is read from.
+	FS fs.FS
+
+	// Path is the path to the migration file within FS. It is empty for Go migrations.
+	Path string
+
+	// GoMigration is set instead of FS and Path for migrations registered with Register.
+	GoMigration *goMigration
+}
+
+// migrationFileExtensions are the file extensions recognized as migration files.
+var migrationFileExtensions = []string{".json", ".yaml", ".yml"}
+
+// migrationFolder returns the file system and directory migration files are read
+// from. The file system is nil if there is no migration folder.
+func migrationFolder(options MigrationOptions) (fs.FS, string) {
+	if options.MigrationFS != nil {
+		if options.MigrationFolder == "" {
+			return options.MigrationFS, "."
+		}
+		return options.MigrationFS, options.MigrationFolder
+	}
+
+	if options.MigrationFolder == "" {
+		return nil, ""
+	}
+	return os.DirFS(options.MigrationFolder), "."
+}
+
+// listMigrationFiles returns the migration files in dir in filename order.
+// Files with an unrecognized suffix are skipped with a warning.
+func listMigrationFiles(fsys fs.FS, dir string, log *slog.Logger) ([]migrationFile, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
 
-			// Parse the migration file
-			migrationData := &Migration{}
-			err = json.Unmarshal(migrationFile, migrationData)
+	var migrationFiles []migrationFile
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		extension := path.Ext(entry.Name())
+		// Import files may sit next to the migration files or in a subfolder
+		if entry.IsDir() || slices.Contains(importFileExtensions, extension) {
+			continue
+		}
+		if !slices.Contains(migrationFileExtensions, extension) {
+			log.Warn("unrecognized file suffix for migration file, skipping", "file", entry.Name())
+			continue
+		}
+
+		migrationNumber := strings.TrimSuffix(entry.Name(), extension)
+		if other, exists := seen[migrationNumber]; exists {
+			return nil, fmt.Errorf("migration %s is defined by both %s and %s", migrationNumber, other, entry.Name())
+		}
+		seen[migrationNumber] = entry.Name()
+
+		migrationFiles = append(migrationFiles, migrationFile{
+			MigrationNumber: migrationNumber,
+			FS:              fsys,
+			Path:            path.Join(dir, entry.Name()),
+		})
+	}
+
+	return migrationFiles, nil
+}
+
+// getMigrationCollection returns the collection tracking applied migrations,
+// creating it if it doesn't exist yet.
+func getMigrationCollection(ctx context.Context, db arangodb.Database, name string) (arangodb.Collection, error) {
+	migrationColl, err := db.GetCollection(ctx, name, &arangodb.GetCollectionOptions{
+		SkipExistCheck: false,
+	})
+	if err != nil {
+		if shared.IsNotFound(err) {
+			migrationColl, err = db.CreateCollection(ctx, name, &arangodb.CreateCollectionProperties{
+				Type: arangodb.CollectionTypeDocument,
+			})
 			if err != nil {
-				return nil, nil, err
+				return nil, fmt.Errorf("failed to create migration collection in specified db: %w", err)
 			}
+		} else {
+			return nil, err
+		}
+	}
 
-			// Validate migration structure
-			if len(migrationData.Up) == 0 {
-				return nil, nil, fmt.Errorf("migration file %s does not include a valid 'up' list of migrations to apply", migrationNumber)
-			}
+	return migrationColl, nil
+}
 
-			if len(migrationData.Down) > 0 {
-				return nil, nil, fmt.Errorf("migration file %s has a 'down' list of migrations, but down migrations are not yet supported", migrationNumber)
-			}
+// readAppliedMigrations returns every migration recorded in the migration collection.
+func readAppliedMigrations(ctx context.Context, db arangodb.Database, collectionName string) ([]AppliedMigration, error) {
+	cursor, err := db.Query(ctx, "FOR m IN @@collection RETURN m", &arangodb.QueryOptions{
+		BindVars: map[string]interface{}{
+			"@collection": collectionName,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	defer cursor.Close()
 
-			pendingMigrations = append(pendingMigrations, PendingMigration{
-				MigrationNumber: migrationNumber,
-				Migration:       migrationData,
-				Hash:            hash,
-				FilePath:        fullpath,
-			})
+	var appliedMigrations []AppliedMigration
+	for cursor.HasMore() {
+		var appliedMigration AppliedMigration
+		_, err := cursor.ReadDocument(ctx, &appliedMigration)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read applied migration: %w", err)
+		}
+		appliedMigrations = append(appliedMigrations, appliedMigration)
+	}
 
-		} else {
-			logrus.Warnf("unrecognized file suffix for migration file: %s, skipping...", migration.Name())
+	return appliedMigrations, nil
+}
+
+// migrationVersion extracts the numeric prefix from a migration number
+// (e.g., "000002_create_posts" -> 2).
+func migrationVersion(migrationNumber string) (int, error) {
+	digits := migrationNumber
+	for i, r := range migrationNumber {
+		if r < '0' || r > '9' {
+			digits = migrationNumber[:i]
+			break
 		}
 	}
 
-	return pendingMigrations, migrationColl, nil
+	if digits == "" {
+		return 0, fmt.Errorf("migration %s does not start with a numeric prefix", migrationNumber)
+	}
+
+	return strconv.Atoi(digits)
+}
+
+// readMigrationFile reads and parses a single migration file.
+func readMigrationFile(fsys fs.FS, name string) (*Migration, error) {
+	migrationFile, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMigration(name, migrationFile)
+}
+
+// parseMigration parses the contents of a migration file, choosing JSON or YAML
+// by the file extension.
+//
+// YAML migrations are converted to JSON before decoding, so both formats produce
+// exactly the same Migration, including the types of values in operation options.
+func parseMigration(name string, data []byte) (*Migration, error) {
+	switch path.Ext(name) {
+	case ".yaml", ".yml":
+		var document interface{}
+		err := yaml.Unmarshal(data, &document)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err = json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert YAML migration to JSON: %w", err)
+		}
+	}
+
+	migrationData := &Migration{}
+	err := json.Unmarshal(data, migrationData)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrationData, nil
 }
 
 func MigrateArangoDatabase(ctx context.Context, db arangodb.Database, options MigrationOptions) error {
+	return New(db, WithMigrationOptions(options)).Up(ctx)
+}
+
+// Up applies all pending migrations like MigrateArangoDatabase, with the operation
+// handlers and hooks of the Migrator.
+func (m *Migrator) Up(ctx context.Context) error {
+	db, options := m.db, m.options
+	log := options.logger()
+	start := time.Now()
+
+	if options.DryRun {
+		plan, err := m.Plan(ctx)
+		if err != nil {
+			return err
+		}
+
+		logPlan(log, plan)
+		if plan.HasErrors() {
+			return fmt.Errorf("dry run found operations that would fail")
+		}
+		return nil
+	}
+
+	// Make sure no other process is migrating the same database
+	lock, err := acquireMigrationLock(ctx, db, options)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+	ctx = lock.ctx
+
 	// Collect all pending migrations
 	pendingMigrations, migrationColl, err := collectPendingMigrations(ctx, db, options)
 	if err != nil {
@@ -330,7 +731,7 @@ func MigrateArangoDatabase(ctx context.Context, db arangodb.Database, options Mi
 	}
 
 	if len(pendingMigrations) == 0 {
-		logrus.Info("no pending migrations to apply")
+		log.Info("no pending migrations to apply")
 		return nil
 	}
 
@@ -344,195 +745,170 @@ func MigrateArangoDatabase(ctx context.Context, db arangodb.Database, options Mi
 		migrationNumber := pendingMigration.MigrationNumber
 		migration := pendingMigration.Migration
 
-		logrus.Infof("applying migration %s...", migrationNumber)
+		log.Info("applying migration", "migration", migrationNumber)
+		migrationStart := time.Now()
+		finish := m.startMigration(ctx, MigrationEvent{
+			MigrationNumber: migrationNumber,
+			Description:     migration.Description,
+			Direction:       DirectionUp,
+		})
 
 		// Track operations for this migration
 		var migrationOperations []OperationResult
 
-		// Apply each operation in the migration
-		for _, operation := range migration.Up {
-			var operationResult OperationResult
-			var err error
-
-			switch operation.Type {
-			case "createCollection":
-				operationResult, err = createCollectionWithTracking(ctx, db, operation.Name, operation.Options)
-			case "createPersistentIndex":
-				operationResult, err = createPersistentIndexWithTracking(ctx, db, operation.Name, operation.Options)
-			case "createGeoIndex":
-				operationResult, err = createGeoIndexWithTracking(ctx, db, operation.Name, operation.Options)
-			case "createGraph":
-				operationResult, err = createGraphWithTracking(ctx, db, operation.Name, operation.Options)
-			case "addEdgeDefinition":
-				operationResult, err = addEdgeDefinitionWithTracking(ctx, db, operation.Name, operation.Options)
-			case "deleteIndex":
-				operationResult, err = deleteIndexWithTracking(ctx, db, operation.Name, operation.Options)
-			case "deleteEdgeDefinition":
-				operationResult, err = deleteEdgeDefinitionWithTracking(ctx, db, operation.Name, operation.Options)
-			case "deleteCollection":
-				operationResult, err = deleteCollectionWithTracking(ctx, db, operation.Name)
-			case "addDocument":
-				operationResult, err = addDocumentWithTracking(ctx, db, operation.Name, operation.Options)
-			case "updateDocument":
-				operationResult, err = updateDocumentWithTracking(ctx, db, operation.Name, operation.Options)
-			case "deleteDocument":
-				operationResult, err = deleteDocumentWithTracking(ctx, db, operation.Name, operation.Options)
-			default:
-				err = fmt.Errorf("unsupported operation type: %s", operation.Type)
-			}
-
+		if migration.Transactional {
+			// Nothing of a failed transactional migration is left to roll back
+			migrationOperations, err = m.applyTransactionalOperations(ctx, migrationNumber, DirectionUp, migration.Up)
 			if err != nil {
-				logrus.Errorf("migration operation failed for migration %s on %s: %v", migrationNumber, operation.Type, err)
+				log.Error("transactional migration failed", "migration", migrationNumber, "error", err)
 
 				if options.AutoRollback {
-					logrus.Error("auto-rollback enabled, rolling back all applied migrations...")
-					rollbackErr := autoRollback(ctx, db, appliedOperations)
+					log.Error("auto-rollback enabled, rolling back all applied migrations")
+					rollbackErr := m.rollbackBatch(ctx, migrationColl, appliedMigrations, appliedOperations)
 					if rollbackErr != nil {
-						logrus.Errorf("failed to auto-rollback migrations: %v", rollbackErr)
-						logrus.Error("database may be in an inconsistent state")
-						return fmt.Errorf("failed to auto-rollback migrations: %v", rollbackErr)
+						return finish(errors.Join(migrationError(migrationNumber, DirectionUp, err), rollbackErr))
 					}
-					return fmt.Errorf("migration operation failed for migration %s: %v", migrationNumber, err)
-				} else {
-					// Legacy rollback behavior - only rollback operations from current migration
-					logrus.Error("rolling back applied operations from current migration...")
-					// Convert Operation to OperationResult for legacy rollback
-					legacyOperation := OperationResult{
-						Type:    operation.Type,
-						Name:    operation.Name,
-						Options: operation.Options,
-					}
-					rollbackErr := autoRollback(ctx, db, []OperationResult{legacyOperation})
-					if rollbackErr != nil {
-						logrus.Errorf("failed to rollback migration: %v", rollbackErr)
-						logrus.Error("database may be in an unclean state")
-						return fmt.Errorf("failed to rollback migration: %v", rollbackErr)
-					}
-					return fmt.Errorf("migration operation failed for migration %s: %v", migrationNumber, err)
 				}
+				return finish(migrationError(migrationNumber, DirectionUp, err))
 			}
+			appliedOperations = append(appliedOperations, migrationOperations...)
+		} else {
+			// Apply each operation in the migration
+			for i, operation := range migration.Up {
+				event := OperationEvent{MigrationNumber: migrationNumber, Direction: DirectionUp, Index: i, Operation: operation}
+				operationResult, err := m.runOperation(ctx, event, m.applyOperation)
+				if err != nil {
+					log.Error("migration operation failed", "migration", migrationNumber, "operation", operation.Type, "name", operation.Name, "error", err)
+
+					if options.AutoRollback {
+						log.Error("auto-rollback enabled, rolling back all applied migrations")
+						rollbackErr := m.rollbackBatch(ctx, migrationColl, appliedMigrations, appliedOperations)
+						if rollbackErr != nil {
+							return finish(errors.Join(operationError(migrationNumber, DirectionUp, i, operation, err), rollbackErr))
+						}
+						return finish(operationError(migrationNumber, DirectionUp, i, operation, err))
+					} else {
+						// Undo the partial work of the failed migration; with CommitEachMigration
+						// earlier migrations are already recorded, so the next run resumes from
+						// it. The failed operation itself is not rolled back, since what it would
+						// have removed again may have existed before, like the collection a
+						// createCollection failed on.
+						log.Error("rolling back applied operations from current migration", "migration", migrationNumber)
+						rollbackErr := m.autoRollback(ctx, migrationOperations)
+						if rollbackErr != nil {
+							log.Error("failed to rollback migration, database may be in an unclean state", "migration", migrationNumber, "error", rollbackErr)
+							return finish(errors.Join(operationError(migrationNumber, DirectionUp, i, operation, err), fmt.Errorf("failed to rollback migration: %w", rollbackErr)))
+						}
+						return finish(operationError(migrationNumber, DirectionUp, i, operation, err))
+					}
+				}
 
-			// Track the operation result
-			operationResult.Type = operation.Type
-			operationResult.Name = operation.Name
-			operationResult.Options = operation.Options
-			migrationOperations = append(migrationOperations, operationResult)
-			appliedOperations = append(appliedOperations, operationResult)
+				// Track the operation result
+				migrationOperations = append(migrationOperations, operationResult)
+				appliedOperations = append(appliedOperations, operationResult)
+			}
 		}
 
-		// Store migration for later application (only if entire batch succeeds)
-		appliedMigrations = append(appliedMigrations, AppliedMigration{
+		appliedMigration := AppliedMigration{
 			MigrationNumber:  migrationNumber,
 			AppliedAt:        time.Now(),
 			Sha256:           pendingMigration.Hash,
 			OperationResults: migrationOperations,
-		})
-		logrus.Infof("migration %s applied successfully.", migrationNumber)
+		}
+
+		// In per-migration commit mode, record the migration right away so that
+		// a later failure doesn't cause it to be applied again
+		if options.CommitEachMigration {
+			_, err := migrationColl.CreateDocument(ctx, &appliedMigration)
+			if err != nil {
+				err = fmt.Errorf("failed to mark migration as applied: %w", err)
+				log.Error("failed to record migration", "migration", migrationNumber, "error", err)
+
+				// An unrecorded migration would be applied again by the next run
+				var rollbackErr error
+				if options.AutoRollback {
+					log.Error("auto-rollback enabled, rolling back all applied migrations")
+					rollbackErr = m.rollbackBatch(ctx, migrationColl, appliedMigrations, appliedOperations)
+				} else {
+					log.Error("rolling back applied operations from current migration", "migration", migrationNumber)
+					rollbackErr = m.autoRollback(ctx, migrationOperations)
+					if rollbackErr != nil {
+						rollbackErr = fmt.Errorf("failed to rollback migration: %w", rollbackErr)
+					}
+				}
+				if rollbackErr != nil {
+					return finish(errors.Join(migrationError(migrationNumber, DirectionUp, err), rollbackErr))
+				}
+				return finish(migrationError(migrationNumber, DirectionUp, err))
+			}
+		}
+
+		// Store migration for later application (only if entire batch succeeds)
+		appliedMigrations = append(appliedMigrations, appliedMigration)
+		log.Info("migration applied successfully", "migration", migrationNumber, "duration", time.Since(migrationStart))
+		finish(nil)
 	}
 
 	// Mark all migrations as applied only after the entire batch succeeds
-	for _, appliedMigration := range appliedMigrations {
-		_, err := migrationColl.CreateDocument(ctx, &appliedMigration)
-		if err != nil {
-			return fmt.Errorf("failed to mark migration as applied: %v", err)
+	if !options.CommitEachMigration {
+		for _, appliedMigration := range appliedMigrations {
+			_, err := migrationColl.CreateDocument(ctx, &appliedMigration)
+			if err != nil {
+				return fmt.Errorf("failed to mark migration as applied: %w", err)
+			}
 		}
 	}
 
-	logrus.Infof("all %d migrations applied successfully", len(pendingMigrations))
+	log.Info("all migrations applied successfully", "count", len(pendingMigrations), "duration", time.Since(start))
 	return nil
 }
 
-// autoRollback rolls back all operations in reverse order using the tracked operation results
-func autoRollback(ctx context.Context, db arangodb.Database, appliedOperations []OperationResult) error {
-	logrus.Info("starting auto-rollback of all applied operations...")
+// rollbackBatch rolls back the operations applied by the current batch and removes
+// the records of migrations committed earlier in the batch.
+func (m *Migrator) rollbackBatch(ctx context.Context, migrationColl arangodb.Collection, appliedMigrations []AppliedMigration, appliedOperations []OperationResult) error {
+	err := m.autoRollback(ctx, appliedOperations)
+	if err != nil {
+		m.options.logger().Error("failed to auto-rollback migrations, database may be in an inconsistent state", "error", err)
+		return fmt.Errorf("failed to auto-rollback migrations: %w", err)
+	}
 
-	// Rollback in reverse order (LIFO)
-	for i := len(appliedOperations) - 1; i >= 0; i-- {
-		operation := appliedOperations[i]
-		var err error
-
-		switch operation.Type {
-		case "createCollection":
-			err = deleteCollection(ctx, db, operation.Name)
-		case "createPersistentIndex":
-			err = deleteIndex(ctx, db, operation.Name, operation.Options)
-		case "createGeoIndex":
-			err = deleteIndex(ctx, db, operation.Name, operation.Options)
-		case "createGraph":
-			err = deleteGraph(ctx, db, operation.Name)
-		case "addEdgeDefinition":
-			err = deleteEdgeDefinition(ctx, db, operation.Name, operation.Options)
-		case "addDocument":
-			// Use the tracked document ID for deletion
-			if docID, ok := operation.Result["documentID"].(string); ok {
-				err = deleteDocumentByID(ctx, db, operation.Name, docID)
-			} else {
-				err = deleteDocument(ctx, db, operation.Name, operation.Options)
-			}
-		case "updateDocument":
-			// Restore the original document state
-			if originalDoc, ok := operation.RollbackData["originalDocument"].(map[string]interface{}); ok {
-				err = restoreDocument(ctx, db, operation.Name, originalDoc)
-			} else {
-				err = fmt.Errorf("cannot rollback document update - no original state available")
-			}
-		case "deleteCollection":
-			err = fmt.Errorf("cannot rollback collection deletion")
-		case "deleteIndex":
-			err = fmt.Errorf("cannot rollback index deletion")
-		case "deleteEdgeDefinition":
-			err = fmt.Errorf("cannot rollback edge definition deletion")
-		case "deleteDocument":
-			// Restore the deleted document
-			if originalDoc, ok := operation.RollbackData["originalDocument"].(map[string]interface{}); ok {
-				err = restoreDocument(ctx, db, operation.Name, originalDoc)
-			} else {
-				err = fmt.Errorf("cannot rollback document deletion - no original state available")
+	// Records committed earlier in this batch no longer match the database
+	if m.options.CommitEachMigration {
+		for _, appliedMigration := range appliedMigrations {
+			_, err := migrationColl.DeleteDocument(ctx, appliedMigration.MigrationNumber)
+			if err != nil {
+				return fmt.Errorf("failed to remove applied migration record %s after auto-rollback: %w", appliedMigration.MigrationNumber, err)
 			}
 		}
-
-		if err != nil {
-			logrus.Errorf("failed to rollback operation %s: %v", operation.Type, err)
-			return fmt.Errorf("failed to rollback operation %s: %v", operation.Type, err)
-		}
-
-		logrus.Infof("rolled back operation: %s (%s)", operation.Type, operation.Name)
 	}
 
-	logrus.Info("auto-rollback completed successfully")
 	return nil
 }
 
-func rollback(ctx context.Context, db arangodb.Database, appliedOperations []Operation) error {
-	for _, operation := range appliedOperations {
-		var err error
-		switch operation.Type {
-		case "createCollection":
-			err = deleteCollection(ctx, db, operation.Name)
-		case "createPersistentIndex":
-			err = deleteIndex(ctx, db, operation.Name, operation.Options)
-		case "createGeoIndex":
-			err = deleteIndex(ctx, db, operation.Name, operation.Options)
-		case "createGraph":
-			err = deleteGraph(ctx, db, operation.Name)
-		case "addEdgeDefinition":
-			err = deleteEdgeDefinition(ctx, db, operation.Name, operation.Options)
-		case "addDocument":
-			err = deleteDocument(ctx, db, operation.Name, operation.Options)
-		case "updateDocument":
-			return fmt.Errorf("cannot rollback document update")
-		case "deleteCollection":
-			return fmt.Errorf("cannot rollback collection deletion")
-		case "deleteIndex":
-			return fmt.Errorf("cannot rollback persistent index deletion")
-		case "deleteEdgeDefinition":
-			return fmt.Errorf("cannot rollback edge definition deletion")
+// autoRollback rolls back all operations in reverse order using the tracked operation results
+func (m *Migrator) autoRollback(ctx context.Context, appliedOperations []OperationResult) error {
+	log := m.options.logger()
+	log.Info("starting auto-rollback of all applied operations")
+
+	// Rollback in reverse order (LIFO)
+	for i := len(appliedOperations) - 1; i >= 0; i-- {
+		operation := appliedOperations[i]
+
+		handler, err := m.handler(operation.Type)
+		if err == nil {
+			err = handler.Rollback(ctx, m.db, operation)
 		}
+		m.rolledBack(ctx, operation, err)
 
 		if err != nil {
-			return err
+			log.Error("failed to rollback operation", "operation", operation.Type, "name", operation.Name, "error", err)
+			return &rollbackError{err: fmt.Errorf("failed to rollback operation %s: %w", operation.Type, err)}
 		}
+
+		log.Info("rolled back operation", "operation", operation.Type, "name", operation.Name)
 	}
+
+	log.Info("auto-rollback completed successfully")
 	return nil
 }
 
@@ -556,42 +932,6 @@ func createCollectionWithTracking(ctx context.Context, db arangodb.Database, nam
 	return result, nil
 }
 
-func createPersistentIndexWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
-	result := OperationResult{
-		Type:    "createPersistentIndex",
-		Name:    name,
-		Options: options,
-		Result:  make(map[string]interface{}),
-	}
-
-	err := createPersistentIndex(ctx, db, name, options)
-	if err != nil {
-		return result, err
-	}
-
-	result.Result["indexName"] = name
-	result.Result["collection"] = options["collection"]
-	return result, nil
-}
-
-func createGeoIndexWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
-	result := OperationResult{
-		Type:    "createGeoIndex",
-		Name:    name,
-		Options: options,
-		Result:  make(map[string]interface{}),
-	}
-
-	err := createGeoIndex(ctx, db, name, options)
-	if err != nil {
-		return result, err
-	}
-
-	result.Result["indexName"] = name
-	result.Result["collection"] = options["collection"]
-	return result, nil
-}
-
 func createGraphWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
 	result := OperationResult{
 		Type:    "createGraph",
@@ -623,25 +963,7 @@ func addEdgeDefinitionWithTracking(ctx context.Context, db arangodb.Database, na
 	}
 
 	result.Result["graphName"] = name
-	result.Result["collection"] = options["collection"]
-	return result, nil
-}
-
-func deleteIndexWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
-	result := OperationResult{
-		Type:    "deleteIndex",
-		Name:    name,
-		Options: options,
-		Result:  make(map[string]interface{}),
-	}
-
-	err := deleteIndex(ctx, db, name, options)
-	if err != nil {
-		return result, err
-	}
-
-	result.Result["indexName"] = name
-	result.Result["collection"] = options["collection"]
+	result.Result["collection"] = edgeDefinitionOptions(options)["collection"]
 	return result, nil
 }
 
@@ -659,7 +981,7 @@ func deleteEdgeDefinitionWithTracking(ctx context.Context, db arangodb.Database,
 	}
 
 	result.Result["graphName"] = name
-	result.Result["collection"] = options["collection"]
+	result.Result["collection"] = edgeDefinitionOptions(options)["collection"]
 	return result, nil
 }
 
@@ -680,7 +1002,7 @@ func deleteCollectionWithTracking(ctx context.Context, db arangodb.Database, nam
 	return result, nil
 }
 
-func addDocumentWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+func addDocumentWithTracking(ctx context.Context, db arangodb.DatabaseCollection, name string, options map[string]interface{}) (OperationResult, error) {
 	result := OperationResult{
 		Type:    "addDocument",
 		Name:    name,
@@ -697,7 +1019,7 @@ func addDocumentWithTracking(ctx context.Context, db arangodb.Database, name str
 	// Get the collection
 	coll, err := db.GetCollection(ctx, name, &arangodb.GetCollectionOptions{})
 	if err != nil {
-		return result, fmt.Errorf("failed to get collection '%s' for document addition: %v", name, err)
+		return result, fmt.Errorf("failed to get collection '%s' for document addition: %w", name, err)
 	}
 
 	document, ok := options["document"].(map[string]interface{})
@@ -730,7 +1052,7 @@ func addDocumentWithTracking(ctx context.Context, db arangodb.Database, name str
 	// Create the document and capture the result
 	meta, err := coll.CreateDocument(ctx, document)
 	if err != nil {
-		return result, fmt.Errorf("failed to add document: %v", err)
+		return result, fmt.Errorf("failed to add document: %w", err)
 	}
 
 	// Store the document ID for rollback
@@ -738,7 +1060,7 @@ func addDocumentWithTracking(ctx context.Context, db arangodb.Database, name str
 	return result, nil
 }
 
-func updateDocumentWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+func updateDocumentWithTracking(ctx context.Context, db arangodb.DatabaseCollection, name string, options map[string]interface{}) (OperationResult, error) {
 	result := OperationResult{
 		Type:         "updateDocument",
 		Name:         name,
@@ -750,7 +1072,7 @@ func updateDocumentWithTracking(ctx context.Context, db arangodb.Database, name
 	// Get the collection
 	coll, err := db.GetCollection(ctx, name, &arangodb.GetCollectionOptions{})
 	if err != nil {
-		return result, fmt.Errorf("failed to get collection '%s' for document update: %v", name, err)
+		return result, fmt.Errorf("failed to get collection '%s' for document update: %w", name, err)
 	}
 
 	key, ok := options["_key"].(string)
@@ -762,7 +1084,7 @@ func updateDocumentWithTracking(ctx context.Context, db arangodb.Database, name
 	var originalDoc map[string]interface{}
 	_, err = coll.ReadDocument(ctx, key, &originalDoc)
 	if err != nil {
-		return result, fmt.Errorf("failed to read original document for rollback: %v", err)
+		return result, fmt.Errorf("failed to read original document for rollback: %w", err)
 	}
 
 	// Store original document for rollback
@@ -779,13 +1101,13 @@ func updateDocumentWithTracking(ctx context.Context, db arangodb.Database, name
 	// Update the document
 	_, err = coll.UpdateDocument(ctx, key, options)
 	if err != nil {
-		return result, fmt.Errorf("failed to update document: %v", err)
+		return result, fmt.Errorf("failed to update document: %w", err)
 	}
 
 	return result, nil
 }
 
-func deleteDocumentWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+func deleteDocumentWithTracking(ctx context.Context, db arangodb.DatabaseCollection, name string, options map[string]interface{}) (OperationResult, error) {
 	result := OperationResult{
 		Type:         "deleteDocument",
 		Name:         name,
@@ -797,7 +1119,7 @@ func deleteDocumentWithTracking(ctx context.Context, db arangodb.Database, name
 	// Get the collection
 	coll, err := db.GetCollection(ctx, name, &arangodb.GetCollectionOptions{})
 	if err != nil {
-		return result, fmt.Errorf("failed to get collection '%s' for document deletion: %v", name, err)
+		return result, fmt.Errorf("failed to get collection '%s' for document deletion: %w", name, err)
 	}
 
 	key, ok := options["_key"].(string)
@@ -809,7 +1131,7 @@ func deleteDocumentWithTracking(ctx context.Context, db arangodb.Database, name
 	var originalDoc map[string]interface{}
 	_, err = coll.ReadDocument(ctx, key, &originalDoc)
 	if err != nil {
-		return result, fmt.Errorf("failed to read original document for rollback: %v", err)
+		return result, fmt.Errorf("failed to read original document for rollback: %w", err)
 	}
 
 	// Store original document for rollback
@@ -819,7 +1141,7 @@ func deleteDocumentWithTracking(ctx context.Context, db arangodb.Database, name
 	// Delete the document
 	_, err = coll.DeleteDocument(ctx, key)
 	if err != nil {
-		return result, fmt.Errorf("failed to remove document: %v", err)
+		return result, fmt.Errorf("failed to remove document: %w", err)
 	}
 
 	return result, nil
@@ -829,12 +1151,12 @@ func deleteDocumentWithTracking(ctx context.Context, db arangodb.Database, name
 func deleteDocumentByID(ctx context.Context, db arangodb.Database, collectionName, documentID string) error {
 	coll, err := db.GetCollection(ctx, collectionName, &arangodb.GetCollectionOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get collection '%s' for document deletion: %v", collectionName, err)
+		return fmt.Errorf("failed to get collection '%s' for document deletion: %w", collectionName, err)
 	}
 
 	_, err = coll.DeleteDocument(ctx, documentID)
 	if err != nil {
-		return fmt.Errorf("failed to remove document: %v", err)
+		return fmt.Errorf("failed to remove document: %w", err)
 	}
 
 	return nil
@@ -843,168 +1165,58 @@ func deleteDocumentByID(ctx context.Context, db arangodb.Database, collectionNam
 func restoreDocument(ctx context.Context, db arangodb.Database, collectionName string, document map[string]interface{}) error {
 	coll, err := db.GetCollection(ctx, collectionName, &arangodb.GetCollectionOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get collection '%s' for document restoration: %v", collectionName, err)
+		return fmt.Errorf("failed to get collection '%s' for document restoration: %w", collectionName, err)
 	}
 
 	_, err = coll.CreateDocument(ctx, document)
 	if err != nil {
-		return fmt.Errorf("failed to restore document: %v", err)
-	}
-
-	return nil
-}
-
-func createCollection(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
-	if collType, exists := options["type"]; !exists {
-		return fmt.Errorf("collection type not specified")
-	} else {
-		switch collType {
-		case "document":
-			_, err := db.CreateCollection(ctx, name, &arangodb.CreateCollectionProperties{
-				Type: arangodb.CollectionTypeDocument,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to create document collection: %v", err)
-			}
-		case "edge":
-			_, err := db.CreateCollection(ctx, name, &arangodb.CreateCollectionProperties{
-				Type: arangodb.CollectionTypeEdge,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to create edge collection: %v", err)
-			}
-		default:
-			return fmt.Errorf("unrecognized collection type: %s", collType)
-		}
+		return fmt.Errorf("failed to restore document: %w", err)
 	}
 
 	return nil
 }
 
-func deleteCollection(ctx context.Context, db arangodb.Database, name string) error {
-	coll, err := db.GetCollection(ctx, name, &arangodb.GetCollectionOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to get collection '%s' for delete: %v", name, err)
-	}
-
-	return coll.Remove(ctx)
-}
-
-//	{
-//		"type": "createPersistentIndex",
-//		"name": "idx_unique_usernames",
-//		"options": {
-//		  "collection": "users",
-//		  "fields": ["normalizedUserName"],
-//		  "unique": true,
-//		  "sparse": true
-//		}
-//	}
-func createPersistentIndex(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
-	collName, ok := options["collection"].(string)
-	if !ok {
-		return fmt.Errorf("collection name missing or not a string")
-	}
-
-	coll, err := db.GetCollection(ctx, collName, &arangodb.GetCollectionOptions{})
+// replaceDocument replaces a document that still exists with its original content.
+func replaceDocument(ctx context.Context, db arangodb.Database, collectionName string, document map[string]interface{}) error {
+	coll, err := db.GetCollection(ctx, collectionName, &arangodb.GetCollectionOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get collection '%s' for index creation: %v", collName, err)
+		return fmt.Errorf("failed to get collection '%s' for document restoration: %w", collectionName, err)
 	}
 
-	fields, ok := getSlice[string](options, "fields")
+	key, ok := document["_key"].(string)
 	if !ok {
-		return fmt.Errorf("fields option missing or not a string array")
+		return fmt.Errorf("original document has no key")
 	}
 
-	indexOptions := arangodb.CreatePersistentIndexOptions{
-		Name: name,
-	}
-
-	if boolOpt, exists := options["unique"]; exists {
-		if unique, ok := boolOpt.(bool); ok {
-			indexOptions.Unique = &unique
-		} else {
-			return fmt.Errorf("unique option not a boolean")
-		}
-	}
-
-	if boolOpt, exists := options["sparse"]; exists {
-		if sparse, ok := boolOpt.(bool); ok {
-			indexOptions.Sparse = &sparse
-		} else {
-			return fmt.Errorf("sparse option not a boolean")
-		}
-	}
-
-	_, _, err = coll.EnsurePersistentIndex(ctx, fields, &indexOptions)
+	_, err = coll.ReplaceDocument(ctx, key, document)
 	if err != nil {
-		return fmt.Errorf("failed to create persistent index: %v", err)
+		return fmt.Errorf("failed to restore document: %w", err)
 	}
 
 	return nil
 }
 
-//	{
-//		"type": "createGeoIndex",
-//		"name": "idx_event_location",
-//		"options": {
-//		  "collection": "events",
-//		  "fields": ["location"]
-//		}
-//	}
-func createGeoIndex(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
-	collName, ok := options["collection"].(string)
-	if !ok {
-		return fmt.Errorf("collection name missing or not a string")
-	}
-
-	coll, err := db.GetCollection(ctx, collName, &arangodb.GetCollectionOptions{})
+func createCollection(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
+	props, err := collectionProperties(options)
 	if err != nil {
-		return fmt.Errorf("failed to get collection '%s' for index creation: %v", collName, err)
-	}
-
-	fields, ok := getSlice[string](options, "fields")
-	if !ok {
-		return fmt.Errorf("fields option missing or not a string array")
-	}
-
-	indexOptions := arangodb.CreateGeoIndexOptions{
-		Name: name,
-	}
-
-	if boolOpt, exists := options["geoJson"]; exists {
-		if geoJson, ok := boolOpt.(bool); ok {
-			indexOptions.GeoJSON = &geoJson
-		} else {
-			return fmt.Errorf("geoJson option not a boolean")
-		}
+		return err
 	}
 
-	_, _, err = coll.EnsureGeoIndex(ctx, fields, &indexOptions)
+	_, err = db.CreateCollection(ctx, name, props)
 	if err != nil {
-		return fmt.Errorf("failed to create persistent index: %v", err)
+		return fmt.Errorf("failed to create %s collection: %w", options["type"], err)
 	}
 
 	return nil
 }
 
-func deleteIndex(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
-	collName, ok := options["collection"].(string)
-	if !ok {
-		return fmt.Errorf("collection name missing or not a string")
-	}
-
-	coll, err := db.GetCollection(ctx, collName, &arangodb.GetCollectionOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to get collection '%s' for index deletion: %v", collName, err)
-	}
-
-	err = coll.DeleteIndex(ctx, name)
+func deleteCollection(ctx context.Context, db arangodb.Database, name string) error {
+	coll, err := db.GetCollection(ctx, name, &arangodb.GetCollectionOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to remove index: %v", err)
+		return fmt.Errorf("failed to get collection '%s' for delete: %w", name, err)
 	}
 
-	return nil
+	return coll.Remove(ctx)
 }
 
 //	{
@@ -1027,37 +1239,14 @@ func deleteIndex(ctx context.Context, db arangodb.Database, name string, options
 //		}
 //	}
 func createGraph(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
-	edges, ok := options["edgeDefinitions"]
-	if !ok {
-		return fmt.Errorf("edgeDefinitions option missing or not an array")
-	}
-
-	bytes, err := json.Marshal(edges)
-	if err != nil {
-		return fmt.Errorf("failed to marshal edge definition options: %v", err)
-	}
-
-	var edgeDefinitions []arangodb.EdgeDefinition
-	err = json.Unmarshal(bytes, &edgeDefinitions)
+	graphDefinition, createOptions, err := graphDefinition(options)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal edge definition options: %v", err)
-	}
-
-	orphanedCollections, ok := getSlice[string](options, "orphanedCollections")
-	if !ok {
-		// Try the old field name for backward compatibility
-		orphanedCollections, ok = getSlice[string](options, "orphanCollections")
-		if !ok {
-			return fmt.Errorf("orphanedCollections option missing or not a string array")
-		}
+		return err
 	}
 
-	_, err = db.CreateGraph(ctx, name, &arangodb.GraphDefinition{
-		EdgeDefinitions:   edgeDefinitions,
-		OrphanCollections: orphanedCollections,
-	}, &arangodb.CreateGraphOptions{})
+	_, err = db.CreateGraph(ctx, name, graphDefinition, createOptions)
 	if err != nil {
-		return fmt.Errorf("failed to create graph '%s': %v", name, err)
+		return fmt.Errorf("failed to create graph '%s': %w", name, err)
 	}
 
 	return nil
@@ -1066,12 +1255,12 @@ func createGraph(ctx context.Context, db arangodb.Database, name string, options
 func deleteGraph(ctx context.Context, db arangodb.Database, name string) error {
 	graph, err := db.Graph(ctx, name, &arangodb.GetGraphOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get graph '%s' for deletion: %v", name, err)
+		return fmt.Errorf("failed to get graph '%s' for deletion: %w", name, err)
 	}
 
 	err = graph.Remove(ctx, &arangodb.RemoveGraphOptions{DropCollections: false})
 	if err != nil {
-		return fmt.Errorf("failed to remove graph: %v", err)
+		return fmt.Errorf("failed to remove graph: %w", err)
 	}
 
 	return nil
@@ -1089,23 +1278,23 @@ func deleteGraph(ctx context.Context, db arangodb.Database, name string) error {
 func addEdgeDefinition(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
 	graph, err := db.Graph(ctx, name, &arangodb.GetGraphOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get graph '%s': %v", name, err)
+		return fmt.Errorf("failed to get graph '%s': %w", name, err)
 	}
 
-	bytes, err := json.Marshal(options)
+	bytes, err := json.Marshal(edgeDefinitionOptions(options))
 	if err != nil {
-		return fmt.Errorf("failed to marshal edge definition options: %v", err)
+		return fmt.Errorf("failed to marshal edge definition options: %w", err)
 	}
 
 	var edgeDefinition arangodb.EdgeDefinition
 	err = json.Unmarshal(bytes, &edgeDefinition)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal edge definition options: %v", err)
+		return fmt.Errorf("failed to unmarshal edge definition options: %w", err)
 	}
 
-	graph.CreateEdgeDefinition(ctx, edgeDefinition.Collection, edgeDefinition.From, edgeDefinition.To, &arangodb.CreateEdgeDefinitionOptions{})
+	_, err = graph.CreateEdgeDefinition(ctx, edgeDefinition.Collection, edgeDefinition.From, edgeDefinition.To, &arangodb.CreateEdgeDefinitionOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to add edge definition: %v", err)
+		return fmt.Errorf("failed to add edge definition: %w", err)
 	}
 
 	return nil
@@ -1114,17 +1303,17 @@ func addEdgeDefinition(ctx context.Context, db arangodb.Database, name string, o
 func deleteEdgeDefinition(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
 	graph, err := db.Graph(ctx, name, &arangodb.GetGraphOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get graph '%s': %v", name, err)
+		return fmt.Errorf("failed to get graph '%s': %w", name, err)
 	}
 
-	collection, ok := options["collection"].(string)
+	collection, ok := edgeDefinitionOptions(options)["collection"].(string)
 	if !ok {
 		return fmt.Errorf("collection option missing or not a string")
 	}
 
 	_, err = graph.DeleteEdgeDefinition(ctx, collection, &arangodb.DeleteEdgeDefinitionOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to remove edge definition: %v", err)
+		return fmt.Errorf("failed to remove edge definition: %w", err)
 	}
 
 	return nil
@@ -1133,7 +1322,7 @@ func deleteEdgeDefinition(ctx context.Context, db arangodb.Database, name string
 func addDocument(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
 	coll, err := db.GetCollection(ctx, name, &arangodb.GetCollectionOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get collection '%s' for document addition: %v", name, err)
+		return fmt.Errorf("failed to get collection '%s' for document addition: %w", name, err)
 	}
 
 	document, ok := options["document"].(map[string]interface{})
@@ -1164,7 +1353,7 @@ func addDocument(ctx context.Context, db arangodb.Database, name string, options
 
 	_, err = coll.CreateDocument(ctx, document)
 	if err != nil {
-		return fmt.Errorf("failed to add document: %v", err)
+		return fmt.Errorf("failed to add document: %w", err)
 	}
 
 	return nil
@@ -1173,7 +1362,7 @@ func addDocument(ctx context.Context, db arangodb.Database, name string, options
 func updateDocument(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
 	coll, err := db.GetCollection(ctx, name, &arangodb.GetCollectionOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get collection '%s' for document addition: %v", name, err)
+		return fmt.Errorf("failed to get collection '%s' for document addition: %w", name, err)
 	}
 
 	key, ok := options["_key"].(string)
@@ -1189,7 +1378,7 @@ func updateDocument(ctx context.Context, db arangodb.Database, name string, opti
 
 	_, err = coll.UpdateDocument(ctx, key, options)
 	if err != nil {
-		return fmt.Errorf("failed to update document: %v", err)
+		return fmt.Errorf("failed to update document: %w", err)
 	}
 
 	return nil
@@ -1198,7 +1387,7 @@ func updateDocument(ctx context.Context, db arangodb.Database, name string, opti
 func deleteDocument(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
 	coll, err := db.GetCollection(ctx, name, &arangodb.GetCollectionOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get collection '%s' for document deletion: %v", name, err)
+		return fmt.Errorf("failed to get collection '%s' for document deletion: %w", name, err)
 	}
 
 	key, ok := options["_key"].(string)
@@ -1208,14 +1397,52 @@ func deleteDocument(ctx context.Context, db arangodb.Database, name string, opti
 
 	_, err = coll.DeleteDocument(ctx, key)
 	if err != nil {
-		return fmt.Errorf("failed to remove document: %v", err)
+		return fmt.Errorf("failed to remove document: %w", err)
 	}
 
 	return nil
 }
 
-func getFileSHA256(filePath string) (string, error) {
-	file, err := os.Open(filePath)
+// getMigrationSHA256 returns the hash recorded for a migration file. The files read
+// by its importDocuments operations are hashed with it, so changes to them are
+// detected too. Migrations without import files keep the hash of the file alone.
+func getMigrationSHA256(fsys fs.FS, name string) (string, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return "", err
+	}
+
+	migration, err := parseMigration(name, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse migration file: %w", err)
+	}
+
+	var importFiles []string
+	for _, operation := range slices.Concat(migration.Up, migration.Down) {
+		if file, ok := operation.Options["file"].(string); ok && operation.Type == "importDocuments" {
+			importFiles = append(importFiles, file)
+		}
+	}
+
+	hash := sha256.New()
+	hash.Write(data)
+	if len(importFiles) == 0 {
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	for _, file := range importFiles {
+		fileHash, err := getFileSHA256(fsys, path.Join(path.Dir(name), file))
+		if err != nil {
+			return "", fmt.Errorf("failed to hash import file %s: %w", file, err)
+		}
+		fmt.Fprintf(hash, "\n%s %s", file, fileHash)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func getFileSHA256(fsys fs.FS, name string) (string, error) {
+	file, err := fsys.Open(name)
 	if err != nil {
 		return "", err
 	}
@@ -1232,6 +1459,44 @@ func getFileSHA256(filePath string) (string, error) {
 	return hashString, nil
 }
 
+// edgeDefinitionOptions returns the edge definition of an addEdgeDefinition or
+// deleteEdgeDefinition operation, which may be nested in an "edgeDefinition" object
+// or given directly in the operation options.
+func edgeDefinitionOptions(options map[string]interface{}) map[string]interface{} {
+	if nested, ok := options["edgeDefinition"].(map[string]interface{}); ok {
+		return nested
+	}
+	return options
+}
+
+// getOrphanedCollections returns the orphan collections of a createGraph operation.
+// They are optional and may also be given under the old "orphanCollections" name.
+func getOrphanedCollections(options map[string]interface{}) ([]string, error) {
+	for _, key := range []string{"orphanedCollections", "orphanCollections"} {
+		if _, exists := options[key]; !exists {
+			continue
+		}
+		orphanedCollections, ok := getSlice[string](options, key)
+		if !ok {
+			return nil, fmt.Errorf("%s option not a string array", key)
+		}
+		return orphanedCollections, nil
+	}
+	return []string{}, nil
+}
+
+// omitOptions returns a copy of options without the given keys, for options that
+// must not be recorded with the applied migration.
+func omitOptions(options map[string]interface{}, keys ...string) map[string]interface{} {
+	recorded := make(map[string]interface{}, len(options))
+	for key, value := range options {
+		if !slices.Contains(keys, key) {
+			recorded[key] = value
+		}
+	}
+	return recorded
+}
+
 func getSlice[T any](m map[string]interface{}, key string) ([]T, bool) {
 	raw, exists := m[key]
 	if !exists {
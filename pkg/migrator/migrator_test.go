@@ -2,9 +2,14 @@ package migrator
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/FramnkRulez/go-arangodb-migrator/pkg/migrator/testutil"
@@ -82,7 +87,7 @@ func TestGetFileSHA256(t *testing.T) {
 	require.NoError(t, err)
 
 	// Get the hash
-	hash, err := getFileSHA256(testFile)
+	hash, err := getFileSHA256(os.DirFS(tempDir), "test.txt")
 	require.NoError(t, err)
 
 	// SHA256 of "test content"
@@ -114,6 +119,203 @@ func TestGetSlice(t *testing.T) {
 	assert.Nil(t, missing)
 }
 
+// TestMigrationVersion tests numeric prefix extraction from migration numbers
+func TestMigrationVersion(t *testing.T) {
+	version, err := migrationVersion("000002_create_posts")
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	version, err = migrationVersion("000010")
+	require.NoError(t, err)
+	assert.Equal(t, 10, version)
+
+	version, err = migrationVersion("0")
+	require.NoError(t, err)
+	assert.Equal(t, 0, version)
+
+	_, err = migrationVersion("initial_schema")
+	assert.Error(t, err)
+
+	_, err = migrationVersion("")
+	assert.Error(t, err)
+}
+
+// TestMigrationFileYAML tests that YAML and JSON migrations parse to the same Migration
+func TestMigrationFileYAML(t *testing.T) {
+	jsonMigration := `{
+		"description": "Create users",
+		"up": [
+			{
+				"type": "createPersistentIndex",
+				"name": "idx_email",
+				"options": {
+					"collection": "users",
+					"fields": ["email"],
+					"unique": true,
+					"minLength": 3
+				}
+			}
+		],
+		"down": [
+			{
+				"type": "deleteIndex",
+				"name": "idx_email",
+				"options": {
+					"collection": "users"
+				}
+			}
+		]
+	}`
+
+	yamlMigration := `# Comments are allowed in YAML migrations
+description: Create users
+up:
+  - type: createPersistentIndex
+    name: idx_email
+    options:
+      collection: users
+      fields:
+        - email
+      unique: true
+      minLength: 3
+down:
+  - type: deleteIndex
+    name: idx_email
+    options:
+      collection: users
+`
+
+	fromJSON, err := parseMigration("000001.json", []byte(jsonMigration))
+	require.NoError(t, err)
+
+	for _, name := range []string{"000001.yaml", "000001.yml"} {
+		fromYAML, err := parseMigration(name, []byte(yamlMigration))
+		require.NoError(t, err)
+		assert.Equal(t, fromJSON, fromYAML)
+	}
+
+	_, err = parseMigration("000001.yaml", []byte("up: [unclosed"))
+	assert.Error(t, err)
+}
+
+// TestMigrationFileDuplicateNumber tests that one migration number can't have two files
+func TestMigrationFileDuplicateNumber(t *testing.T) {
+	tempDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(tempDir, "000001_users.json"), []byte(`{}`), 0644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "000001_users.yaml"), []byte(`{}`), 0644)
+	require.NoError(t, err)
+
+	_, err = listMigrationFiles(os.DirFS(tempDir), ".", defaultLogger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "000001_users")
+}
+
+// TestMigrationTargetVersion tests limiting the pending migrations to a target version
+// and a number of steps
+func TestMigrationTargetVersion(t *testing.T) {
+	ctx := context.Background()
+	migrationFS := fstest.MapFS{}
+	for _, name := range []string{"000001_users", "000002_posts", "000003_comments", "000004_tags"} {
+		migrationFS[name+".json"] = &fstest.MapFile{
+			Data: []byte(`{"up": [{"type": "createCollection", "name": "` + name[7:] + `"}]}`),
+		}
+	}
+
+	pendingNumbers := func(options MigrationOptions) []string {
+		options.MigrationFS = migrationFS
+		pendingMigrations, err := findPendingMigrations(ctx, nil, options)
+		require.NoError(t, err)
+
+		var numbers []string
+		for _, pendingMigration := range pendingMigrations {
+			numbers = append(numbers, pendingMigration.MigrationNumber)
+		}
+		return numbers
+	}
+
+	assert.Len(t, pendingNumbers(MigrationOptions{}), 4)
+	assert.Equal(t, []string{"000001_users", "000002_posts"}, pendingNumbers(MigrationOptions{TargetVersion: "000002"}))
+	assert.Equal(t, []string{"000001_users", "000002_posts", "000003_comments"}, pendingNumbers(MigrationOptions{TargetVersion: "3_comments"}))
+	assert.Equal(t, []string{"000001_users"}, pendingNumbers(MigrationOptions{Steps: 1}))
+	assert.Equal(t, []string{"000001_users", "000002_posts"}, pendingNumbers(MigrationOptions{TargetVersion: "2", Steps: 3}))
+	assert.Equal(t, []string{"000001_users", "000002_posts", "000003_comments"}, pendingNumbers(MigrationOptions{TargetVersion: "4", Steps: 3}))
+
+	_, err := findPendingMigrations(ctx, nil, MigrationOptions{MigrationFS: migrationFS, TargetVersion: "000005"})
+	assert.ErrorContains(t, err, "target version 000005 does not match any migration")
+
+	_, err = findPendingMigrations(ctx, nil, MigrationOptions{MigrationFS: migrationFS, TargetVersion: "latest"})
+	assert.ErrorContains(t, err, "invalid target version")
+
+	_, err = findPendingMigrations(ctx, nil, MigrationOptions{MigrationFS: migrationFS, Steps: -1})
+	assert.ErrorContains(t, err, "steps must not be negative")
+}
+
+// TestMigrationFS tests listing and hashing migration files from an fs.FS
+func TestMigrationFS(t *testing.T) {
+	migration := []byte(`{"description": "Create users", "up": [{"type": "createCollection", "name": "users", "options": {"type": "document"}}]}`)
+	fsys := fstest.MapFS{
+		"migrations/000001_users.json": {Data: migration},
+		"migrations/000002_posts.yaml": {Data: []byte("description: Create posts\n")},
+		"migrations/README.md":         {Data: []byte("# Migrations")},
+		"other/000003_ignored.json":    {Data: migration},
+	}
+
+	migrationFiles, err := listMigrations(MigrationOptions{
+		MigrationFS:     fsys,
+		MigrationFolder: "migrations",
+	})
+	require.NoError(t, err)
+	require.Len(t, migrationFiles, 2)
+	assert.Equal(t, "000001_users", migrationFiles[0].MigrationNumber)
+	assert.Equal(t, "migrations/000001_users.json", migrationFiles[0].Path)
+	assert.Equal(t, "000002_posts", migrationFiles[1].MigrationNumber)
+
+	// The hash is computed over the bytes in the file system
+	hash, err := getFileSHA256(migrationFiles[0].FS, migrationFiles[0].Path)
+	require.NoError(t, err)
+	expected := sha256.Sum256(migration)
+	assert.Equal(t, hex.EncodeToString(expected[:]), hash)
+
+	// Migrations without import files are recorded with the hash of the file alone
+	migrationHash, err := getMigrationSHA256(migrationFiles[0].FS, migrationFiles[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, hash, migrationHash)
+
+	// The import files of a migration are hashed with it
+	importFS := fstest.MapFS{
+		"migrations/000003_countries.yaml": {Data: []byte("up:\n  - type: importDocuments\n    name: countries\n    options:\n      file: data/countries.jsonl\n")},
+		"migrations/data/countries.jsonl":  {Data: []byte(`{"_key": "de", "name": "Germany"}`)},
+	}
+	importHash, err := getMigrationSHA256(importFS, "migrations/000003_countries.yaml")
+	require.NoError(t, err)
+	fileHash, err := getFileSHA256(importFS, "migrations/000003_countries.yaml")
+	require.NoError(t, err)
+	assert.NotEqual(t, fileHash, importHash)
+
+	importFS["migrations/data/countries.jsonl"] = &fstest.MapFile{Data: []byte(`{"_key": "de", "name": "Deutschland"}`)}
+	changedHash, err := getMigrationSHA256(importFS, "migrations/000003_countries.yaml")
+	require.NoError(t, err)
+	assert.NotEqual(t, importHash, changedHash)
+
+	delete(importFS, "migrations/data/countries.jsonl")
+	_, err = getMigrationSHA256(importFS, "migrations/000003_countries.yaml")
+	assert.Error(t, err)
+
+	parsed, err := readMigrationFile(migrationFiles[0].FS, migrationFiles[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "Create users", parsed.Description)
+
+	// Without a folder, the root of the file system is used
+	migrationFiles, err = listMigrations(MigrationOptions{
+		MigrationFS: fstest.MapFS{"000001_users.json": {Data: migration}},
+	})
+	require.NoError(t, err)
+	require.Len(t, migrationFiles, 1)
+	assert.Equal(t, "000001_users.json", migrationFiles[0].Path)
+}
+
 // TestMigrateArangoDatabase tests the main migration function with a real ArangoDB container
 func TestMigrateArangoDatabase(t *testing.T) {
 	// Skip if Docker is not available
@@ -231,6 +433,14 @@ func TestMigrateArangoDatabaseWithInvalidMigration(t *testing.T) {
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "unsupported operation type: invalidOperation")
+	assert.ErrorIs(t, err, ErrUnsupportedOperation)
+
+	var migrationErr *MigrationError
+	require.ErrorAs(t, err, &migrationErr)
+	assert.Equal(t, "000001_invalid", migrationErr.Number)
+	assert.Equal(t, 0, migrationErr.OperationIndex)
+	assert.Equal(t, "invalidOperation", migrationErr.OperationType)
+	assert.Equal(t, "test", migrationErr.Name)
 }
 
 func TestMigrateArangoDatabaseWithModifiedFile(t *testing.T) {
@@ -294,6 +504,7 @@ func TestMigrateArangoDatabaseWithModifiedFile(t *testing.T) {
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "migration file has been modified")
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
 }
 
 func TestMigrateArangoDatabaseWithNonExistentFolder(t *testing.T) {
@@ -389,6 +600,154 @@ func TestMigrateArangoDatabaseWithNonJsonFiles(t *testing.T) {
 	assert.True(t, exists, "Collection should be created")
 }
 
+func TestMigrateArangoDatabaseWithYAMLFiles(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_yaml_files")
+
+	// Mix JSON and YAML migrations in one folder
+	tempDir := t.TempDir()
+
+	jsonMigration := `{
+		"description": "Create users",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "users",
+				"options": {
+					"type": "document"
+				}
+			}
+		]
+	}`
+	err := os.WriteFile(filepath.Join(tempDir, "000001_users.json"), []byte(jsonMigration), 0644)
+	require.NoError(t, err)
+
+	yamlMigration := `description: Add user indexes
+up:
+  # Emails must be unique
+  - type: createPersistentIndex
+    name: idx_email
+    options:
+      collection: users
+      fields: [email]
+      unique: true
+`
+	err = os.WriteFile(filepath.Join(tempDir, "000002_indexes.yaml"), []byte(yamlMigration), 0644)
+	require.NoError(t, err)
+
+	ymlMigration := `description: Add admin user
+up:
+  - type: addDocument
+    name: users
+    options:
+      document:
+        _key: admin
+        email: admin@example.com
+        loginCount: 0
+`
+	err = os.WriteFile(filepath.Join(tempDir, "000003_admin.yml"), []byte(ymlMigration), 0644)
+	require.NoError(t, err)
+
+	options := MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+	}
+
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+
+	coll, err := db.GetCollection(ctx, "users", nil)
+	require.NoError(t, err)
+
+	indexes, err := coll.Indexes(ctx)
+	require.NoError(t, err)
+
+	var found bool
+	for _, index := range indexes {
+		if index.Name == "idx_email" {
+			found = true
+		}
+	}
+	assert.True(t, found, "Index from YAML migration should be created")
+
+	exists, err := coll.DocumentExists(ctx, "admin")
+	require.NoError(t, err)
+	assert.True(t, exists, "Document from .yml migration should be created")
+
+	// Changing only a comment still changes the file hash
+	err = os.WriteFile(filepath.Join(tempDir, "000002_indexes.yaml"), []byte(strings.Replace(yamlMigration, "must be unique", "are unique", 1)), 0644)
+	require.NoError(t, err)
+
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "modified")
+}
+
+func TestMigrateArangoDatabaseWithMigrationFS(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_migration_fs")
+
+	// Migrations embedded in the binary, without any folder on disk
+	fsys := fstest.MapFS{
+		"migrations/000001_users.json": {Data: []byte(`{
+			"description": "Create users",
+			"up": [
+				{
+					"type": "createCollection",
+					"name": "users",
+					"options": {
+						"type": "document"
+					}
+				}
+			]
+		}`)},
+		"migrations/000002_index.yaml": {Data: []byte(`description: Index emails
+up:
+  - type: createPersistentIndex
+    name: idx_email
+    options:
+      collection: users
+      fields: [email]
+`)},
+	}
+
+	options := MigrationOptions{
+		MigrationFS:         fsys,
+		MigrationFolder:     "migrations",
+		MigrationCollection: "migrations",
+	}
+
+	err := MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+
+	exists, err := db.CollectionExists(ctx, "users")
+	require.NoError(t, err)
+	assert.True(t, exists, "Collection should be created")
+
+	statuses, err := Status(ctx, db, options)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	for _, status := range statuses {
+		assert.Equal(t, MigrationStateApplied, status.State)
+	}
+
+	// Running again with the same file system applies nothing
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+}
+
 func TestMigrateArangoDatabaseWithEmptyUpList(t *testing.T) {
 	ctx := context.Background()
 
@@ -453,14 +812,17 @@ func TestMigrateArangoDatabaseWithDownList(t *testing.T) {
 	err := os.WriteFile(filepath.Join(tempDir, "000001_with_down.json"), []byte(downMigration), 0644)
 	require.NoError(t, err)
 
-	// Run migrations - should fail due to down list
+	// Run migrations - down list is accepted and only the up list is applied
 	err = MigrateArangoDatabase(ctx, db, MigrationOptions{
 		MigrationFolder:     tempDir,
 		MigrationCollection: "migrations",
 	})
 
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "has a 'down' list of migrations, but down migrations are not yet supported")
+	require.NoError(t, err)
+
+	exists, err := db.CollectionExists(ctx, "test_collection")
+	require.NoError(t, err)
+	assert.True(t, exists, "Collection should be created by the up list")
 }
 
 func TestMigrateArangoDatabaseWithMissingUpList(t *testing.T) {
@@ -769,3 +1131,434 @@ func TestBackwardCompatibilityWithExistingMigrations(t *testing.T) {
 	_, hasOperationResults = newMigrationDoc["operationResults"]
 	assert.True(t, hasOperationResults, "New migration should have operationResults field")
 }
+
+func TestMigrateArangoDatabaseWithCommitEachMigration(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_commit_each_migration")
+
+	tempDir := t.TempDir()
+
+	firstMigration := `{
+		"description": "First migration - should succeed",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "first_collection",
+				"options": {
+					"type": "document"
+				}
+			}
+		]
+	}`
+
+	secondMigration := `{
+		"description": "Second migration - should succeed",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "second_collection",
+				"options": {
+					"type": "document"
+				}
+			}
+		]
+	}`
+
+	failingMigration := `{
+		"description": "Third migration - should fail",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "third_collection",
+				"options": {
+					"type": "document"
+				}
+			},
+			{
+				"type": "invalidOperation",
+				"name": "test",
+				"options": {}
+			}
+		]
+	}`
+
+	err := os.WriteFile(filepath.Join(tempDir, "000001_first.json"), []byte(firstMigration), 0644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "000002_second.json"), []byte(secondMigration), 0644)
+	require.NoError(t, err)
+	thirdFile := filepath.Join(tempDir, "000003_third.json")
+	err = os.WriteFile(thirdFile, []byte(failingMigration), 0644)
+	require.NoError(t, err)
+
+	options := MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+		CommitEachMigration: true,
+	}
+
+	// Run migrations - the third one fails
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported operation type: invalidOperation")
+
+	migrationsColl, err := db.GetCollection(ctx, "migrations", nil)
+	require.NoError(t, err)
+
+	// The first two migrations are recorded even though the batch failed
+	for _, migrationKey := range []string{"000001_first", "000002_second"} {
+		exists, err := migrationsColl.DocumentExists(ctx, migrationKey)
+		require.NoError(t, err)
+		assert.True(t, exists, "Migration %s should be recorded", migrationKey)
+	}
+
+	exists, err := migrationsColl.DocumentExists(ctx, "000003_third")
+	require.NoError(t, err)
+	assert.False(t, exists, "Failed migration should not be recorded")
+
+	// The partial work of the failed migration is rolled back
+	exists, err = db.CollectionExists(ctx, "third_collection")
+	require.NoError(t, err)
+	assert.False(t, exists, "Collection from the failed migration should have been rolled back")
+
+	// Fix the failed migration and resume
+	fixedMigration := `{
+		"description": "Third migration - fixed",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "third_collection",
+				"options": {
+					"type": "document"
+				}
+			}
+		]
+	}`
+	err = os.WriteFile(thirdFile, []byte(fixedMigration), 0644)
+	require.NoError(t, err)
+
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+
+	for _, collectionName := range []string{"first_collection", "second_collection", "third_collection"} {
+		exists, err := db.CollectionExists(ctx, collectionName)
+		require.NoError(t, err)
+		assert.True(t, exists, "Collection %s should exist", collectionName)
+	}
+}
+
+func TestMigrateArangoDatabaseCommitEachMigrationRecordFails(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_commit_each_record_fails")
+
+	// The migration takes the key of its own record, so recording it fails
+	tempDir := t.TempDir()
+	migration := `{
+		"description": "Create orders and block the migration record",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "orders",
+				"options": {
+					"type": "document"
+				}
+			},
+			{
+				"type": "addDocument",
+				"name": "migrations",
+				"options": {
+					"document": {
+						"_key": "000001_orders"
+					}
+				}
+			}
+		]
+	}`
+	err := os.WriteFile(filepath.Join(tempDir, "000001_orders.json"), []byte(migration), 0644)
+	require.NoError(t, err)
+
+	err = MigrateArangoDatabase(ctx, db, MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+		CommitEachMigration: true,
+	})
+	require.Error(t, err)
+
+	var migrationErr *MigrationError
+	require.ErrorAs(t, err, &migrationErr)
+	assert.Equal(t, "000001_orders", migrationErr.Number)
+	assert.Contains(t, err.Error(), "failed to mark migration as applied")
+
+	// The operations of the unrecorded migration are rolled back
+	exists, err := db.CollectionExists(ctx, "orders")
+	require.NoError(t, err)
+	assert.False(t, exists, "Collection of the unrecorded migration should have been rolled back")
+
+	migrationsColl, err := db.GetCollection(ctx, "migrations", nil)
+	require.NoError(t, err)
+	exists, err = migrationsColl.DocumentExists(ctx, "000001_orders")
+	require.NoError(t, err)
+	assert.False(t, exists, "Migration should not be recorded")
+}
+
+func TestMigrateArangoDatabaseKeepsResourceOfFailedOperation(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_failed_operation_kept")
+
+	// The view exists before the migration that tries to create it
+	err := createArangoSearchView(ctx, db, "posts_view", map[string]interface{}{})
+	require.NoError(t, err)
+
+	tempDir := t.TempDir()
+	migration := `{
+		"description": "Create posts and their view",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "posts",
+				"options": {
+					"type": "document"
+				}
+			},
+			{
+				"type": "createArangoSearchView",
+				"name": "posts_view",
+				"options": {}
+			}
+		]
+	}`
+	err = os.WriteFile(filepath.Join(tempDir, "000001_posts.json"), []byte(migration), 0644)
+	require.NoError(t, err)
+
+	// Neither AutoRollback nor CommitEachMigration is set
+	err = MigrateArangoDatabase(ctx, db, MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+	})
+	require.Error(t, err)
+
+	// The operations before the failed one are rolled back, the failed one is not
+	exists, err := db.CollectionExists(ctx, "posts")
+	require.NoError(t, err)
+	assert.False(t, exists, "Collection of the failed migration should have been rolled back")
+
+	_, err = db.View(ctx, "posts_view")
+	assert.NoError(t, err, "View that existed before the migration should be kept")
+}
+
+func TestMigrateArangoDatabaseWithTransactionalMigration(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_transactional_migration")
+
+	tempDir := t.TempDir()
+
+	schemaMigration := `{
+		"description": "Create users",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "users",
+				"options": {
+					"type": "document"
+				}
+			},
+			{
+				"type": "addDocument",
+				"name": "users",
+				"options": {
+					"document": {
+						"_key": "jane",
+						"role": "user"
+					}
+				}
+			}
+		]
+	}`
+	err := os.WriteFile(filepath.Join(tempDir, "000001_users.json"), []byte(schemaMigration), 0644)
+	require.NoError(t, err)
+
+	// The last operation fails, so the earlier ones must not be committed
+	dataMigration := `{
+		"description": "Promote jane and add an admin",
+		"transactional": true,
+		"up": [
+			{
+				"type": "updateDocument",
+				"name": "users",
+				"options": {
+					"_key": "jane",
+					"role": "admin"
+				}
+			},
+			{
+				"type": "addDocument",
+				"name": "users",
+				"options": {
+					"document": {
+						"_key": "root",
+						"role": "admin"
+					}
+				}
+			},
+			{
+				"type": "deleteDocument",
+				"name": "users",
+				"options": {
+					"_key": "does_not_exist"
+				}
+			}
+		],
+		"down": [
+			{
+				"type": "deleteDocument",
+				"name": "users",
+				"options": {
+					"_key": "root"
+				}
+			},
+			{
+				"type": "updateDocument",
+				"name": "users",
+				"options": {
+					"_key": "jane",
+					"role": "user"
+				}
+			}
+		]
+	}`
+	dataFile := filepath.Join(tempDir, "000002_admins.json")
+	err = os.WriteFile(dataFile, []byte(dataMigration), 0644)
+	require.NoError(t, err)
+
+	options := MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+		CommitEachMigration: true,
+	}
+
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "transaction aborted")
+
+	users, err := db.GetCollection(ctx, "users", nil)
+	require.NoError(t, err)
+
+	var jane map[string]interface{}
+	_, err = users.ReadDocument(ctx, "jane", &jane)
+	require.NoError(t, err)
+	assert.Equal(t, "user", jane["role"], "Update should not have been committed")
+
+	exists, err := users.DocumentExists(ctx, "root")
+	require.NoError(t, err)
+	assert.False(t, exists, "Document should not have been committed")
+
+	// Without the failing operation the whole migration is committed
+	err = os.WriteFile(dataFile, []byte(strings.Replace(dataMigration, `"_key": "does_not_exist"`, `"_key": "jane"`, 1)), 0644)
+	require.NoError(t, err)
+
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+
+	exists, err = users.DocumentExists(ctx, "jane")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = users.DocumentExists(ctx, "root")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	t.Run("down list runs in a transaction", func(t *testing.T) {
+		// jane was deleted by the up list, so the down list fails on its last operation
+		err := RollbackArangoDatabase(ctx, db, options, "1")
+		require.Error(t, err)
+
+		exists, err := users.DocumentExists(ctx, "root")
+		require.NoError(t, err)
+		assert.True(t, exists, "Deletion from the failed down list should not have been committed")
+	})
+}
+
+func TestMigrateArangoDatabaseWithTargetVersion(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_target_version")
+
+	tempDir := t.TempDir()
+	collections := []string{"users", "posts", "comments"}
+	for i, collection := range collections {
+		migration := `{
+			"description": "Create ` + collection + `",
+			"up": [
+				{"type": "createCollection", "name": "` + collection + `", "options": {"type": "document"}}
+			]
+		}`
+		err := os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("%06d_%s.json", i+1, collection)), []byte(migration), 0644)
+		require.NoError(t, err)
+	}
+
+	options := MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+		TargetVersion:       "000002",
+	}
+
+	assertCollections := func(expected ...bool) {
+		t.Helper()
+		for i, collection := range collections {
+			exists, err := db.CollectionExists(ctx, collection)
+			require.NoError(t, err)
+			assert.Equal(t, expected[i], exists, collection)
+		}
+	}
+
+	// Stop after the target version
+	err := MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+	assertCollections(true, true, false)
+
+	// Nothing is left up to the target
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+	assertCollections(true, true, false)
+
+	// Apply the remaining migration one step at a time
+	options.TargetVersion = ""
+	options.Steps = 1
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+	assertCollections(true, true, true)
+
+	statuses, err := Status(ctx, db, options)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.Equal(t, MigrationStateApplied, status.State, status.MigrationNumber)
+	}
+}
@@ -3,8 +3,10 @@ package migrator
 import (
 	"context"
 	"testing"
+	"testing/fstest"
 
 	"github.com/FramnkRulez/go-arangodb-migrator/pkg/migrator/testutil"
+	"github.com/arangodb/go-driver/v2/arangodb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -59,7 +61,7 @@ func TestCreatePersistentIndex(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create a persistent index
-	err = createPersistentIndex(ctx, db, "idx_test_field", map[string]interface{}{
+	_, err = createPersistentIndex(ctx, db, "idx_test_field", map[string]interface{}{
 		"collection": "test_collection",
 		"fields":     []string{"field1"},
 		"unique":     true,
@@ -101,7 +103,7 @@ func TestCreateGeoIndex(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create a geo index
-	err = createGeoIndex(ctx, db, "idx_geo_location", map[string]interface{}{
+	_, err = createGeoIndex(ctx, db, "idx_geo_location", map[string]interface{}{
 		"collection": "test_collection",
 		"fields":     []string{"location"},
 	})
@@ -286,3 +288,741 @@ func TestDeleteDocument(t *testing.T) {
 	require.NoError(t, err)
 	assert.False(t, exists, "Document should be deleted")
 }
+
+func TestExecuteAQL(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_execute_aql")
+
+	// Create a collection with a few documents
+	err := createCollection(ctx, db, "test_collection", map[string]interface{}{
+		"type": "document",
+	})
+	require.NoError(t, err)
+
+	for _, key := range []string{"doc1", "doc2", "doc3"} {
+		err = addDocument(ctx, db, "test_collection", map[string]interface{}{
+			"document": map[string]interface{}{
+				"_key":     key,
+				"username": key,
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	// Rename a field on every document
+	options := map[string]interface{}{
+		"query":         "FOR d IN @@coll UPDATE d WITH { name: d.username, username: null } IN @@coll OPTIONS { keepNull: false }",
+		"bindVars":      map[string]interface{}{"@coll": "test_collection"},
+		"rollbackQuery": "FOR d IN @@coll UPDATE d WITH { username: d.name, name: null } IN @@coll OPTIONS { keepNull: false }",
+		"batchSize":     float64(2),
+		"timeout":       float64(30),
+	}
+	result, err := executeAQLWithTracking(ctx, db, "rename_username", options)
+	require.NoError(t, err)
+
+	stats, ok := result.Result["stats"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, uint64(3), stats["writesExecuted"])
+
+	coll, err := db.GetCollection(ctx, "test_collection", nil)
+	require.NoError(t, err)
+
+	var retrievedDoc map[string]interface{}
+	_, err = coll.ReadDocument(ctx, "doc1", &retrievedDoc)
+	require.NoError(t, err)
+	assert.Equal(t, "doc1", retrievedDoc["name"])
+	assert.NotContains(t, retrievedDoc, "username")
+
+	// The rollback query reuses the bind parameters of the query
+	err = rollbackExecuteAQL(ctx, db, options)
+	require.NoError(t, err)
+
+	retrievedDoc = nil
+	_, err = coll.ReadDocument(ctx, "doc1", &retrievedDoc)
+	require.NoError(t, err)
+	assert.Equal(t, "doc1", retrievedDoc["username"])
+	assert.NotContains(t, retrievedDoc, "name")
+
+	// Without a rollback query the operation can't be rolled back
+	err = rollbackExecuteAQL(ctx, db, map[string]interface{}{
+		"query": "FOR d IN test_collection RETURN d",
+	})
+	assert.Error(t, err)
+}
+
+func TestCreateIndexTypes(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_create_index_types")
+
+	err := createCollection(ctx, db, "test_collection", map[string]interface{}{
+		"type": "document",
+	})
+	require.NoError(t, err)
+
+	indexes := []Operation{
+		{
+			Type: "createPersistentIndex",
+			Name: "idx_persistent",
+			Options: map[string]interface{}{
+				"collection":   "test_collection",
+				"fields":       []interface{}{"email"},
+				"storedValues": []interface{}{"name"},
+				"cacheEnabled": true,
+				"estimates":    false,
+				"deduplicate":  false,
+			},
+		},
+		{
+			Type: "createTTLIndex",
+			Name: "idx_ttl",
+			Options: map[string]interface{}{
+				"collection":  "test_collection",
+				"fields":      []interface{}{"createdAt"},
+				"expireAfter": float64(3600),
+			},
+		},
+		{
+			Type: "createInvertedIndex",
+			Name: "idx_inverted",
+			Options: map[string]interface{}{
+				"collection": "test_collection",
+				"analyzer":   "text_en",
+				"fields": []interface{}{
+					"title",
+					map[string]interface{}{"name": "tags", "analyzer": "identity"},
+				},
+			},
+		},
+		{
+			Type: "createMDIIndex",
+			Name: "idx_mdi",
+			Options: map[string]interface{}{
+				"collection": "test_collection",
+				"fields":     []interface{}{"x", "y"},
+			},
+		},
+		{
+			Type: "createMDIIndex",
+			Name: "idx_mdi_prefixed",
+			Options: map[string]interface{}{
+				"collection":   "test_collection",
+				"fields":       []interface{}{"x", "y"},
+				"prefixFields": []interface{}{"region"},
+			},
+		},
+		{
+			Type: "createFulltextIndex",
+			Name: "idx_fulltext",
+			Options: map[string]interface{}{
+				"collection": "test_collection",
+				"fields":     []interface{}{"title"},
+				"minLength":  float64(3),
+			},
+		},
+	}
+
+	m := New(db, WithMigrationOptions(MigrationOptions{Client: container.Client}))
+	var results []OperationResult
+	for _, index := range indexes {
+		result, err := m.applyOperation(ctx, index)
+		require.NoError(t, err, "failed to create %s", index.Name)
+		results = append(results, result)
+	}
+
+	coll, err := db.GetCollection(ctx, "test_collection", nil)
+	require.NoError(t, err)
+
+	indexNames := func() map[string]bool {
+		existing, err := coll.Indexes(ctx)
+		require.NoError(t, err)
+
+		names := make(map[string]bool)
+		for _, index := range existing {
+			names[index.Name] = true
+		}
+		return names
+	}
+
+	names := indexNames()
+	for _, index := range indexes {
+		assert.True(t, names[index.Name], "index %s should exist", index.Name)
+	}
+
+	// Creating an identical index again doesn't create it, so its rollback keeps it
+	existing, err := m.applyOperation(ctx, indexes[0])
+	require.NoError(t, err)
+	assert.Equal(t, true, results[0].Result["created"])
+	assert.Equal(t, false, existing.Result["created"])
+
+	err = m.autoRollback(ctx, []OperationResult{existing})
+	require.NoError(t, err)
+	assert.True(t, indexNames()[indexes[0].Name], "index %s should have been kept", indexes[0].Name)
+
+	// Every index type is rolled back by deleting the index
+	err = m.autoRollback(ctx, results)
+	require.NoError(t, err)
+
+	names = indexNames()
+	for _, index := range indexes {
+		assert.False(t, names[index.Name], "index %s should have been deleted", index.Name)
+	}
+}
+
+func TestModifyCollection(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_modify_collection")
+
+	// Create a collection with properties besides its type
+	err := createCollection(ctx, db, "test_collection", map[string]interface{}{
+		"type":        "document",
+		"waitForSync": true,
+		"keyOptions": map[string]interface{}{
+			"type": "padded",
+		},
+	})
+	require.NoError(t, err)
+
+	coll, err := db.GetCollection(ctx, "test_collection", nil)
+	require.NoError(t, err)
+
+	props, err := coll.Properties(ctx)
+	require.NoError(t, err)
+	assert.True(t, props.WaitForSync)
+
+	result, err := modifyCollectionWithTracking(ctx, db, "test_collection", map[string]interface{}{
+		"waitForSync":  false,
+		"cacheEnabled": true,
+	})
+	require.NoError(t, err)
+
+	props, err = coll.Properties(ctx)
+	require.NoError(t, err)
+	assert.False(t, props.WaitForSync)
+	assert.True(t, props.CacheEnabled)
+
+	// Rolling back restores the previous properties
+	err = rollbackModifyCollection(ctx, db, defaultLogger, result)
+	require.NoError(t, err)
+
+	props, err = coll.Properties(ctx)
+	require.NoError(t, err)
+	assert.True(t, props.WaitForSync)
+	assert.False(t, props.CacheEnabled)
+}
+
+func TestSearchOperations(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_search_operations")
+
+	for _, name := range []string{"posts", "comments"} {
+		err := createCollection(ctx, db, name, map[string]interface{}{"type": "document"})
+		require.NoError(t, err)
+	}
+
+	analyzerResult, err := createAnalyzerWithTracking(ctx, db, "text_en_no_stem", map[string]interface{}{
+		"type":       "text",
+		"properties": map[string]interface{}{"locale": "en", "stemming": false},
+		"features":   []interface{}{"frequency", "norm", "position"},
+	})
+	require.NoError(t, err)
+
+	// Creating an existing analyzer fails, so rollback can't delete it
+	_, err = createAnalyzerWithTracking(ctx, db, "text_en_no_stem", map[string]interface{}{"type": "identity"})
+	assert.ErrorContains(t, err, "already exists")
+
+	viewResult, err := createViewWithTracking(ctx, db, "createArangoSearchView", "posts_view", map[string]interface{}{
+		"links": map[string]interface{}{
+			"posts": map[string]interface{}{
+				"analyzers": []interface{}{"text_en_no_stem"},
+				"fields":    map[string]interface{}{"title": map[string]interface{}{}},
+			},
+		},
+	}, createArangoSearchView)
+	require.NoError(t, err)
+
+	linkedCollections := func() []string {
+		_, props, err := viewProperties(ctx, db, "posts_view")
+		require.NoError(t, err)
+		var options map[string]interface{}
+		require.NoError(t, remarshal(props, &options))
+		return viewCollections(options)
+	}
+	assert.Equal(t, []string{"posts"}, linkedCollections())
+
+	// Links are merged by collection
+	updateResult, err := updateViewPropertiesWithTracking(ctx, db, "posts_view", map[string]interface{}{
+		"links": map[string]interface{}{
+			"comments": map[string]interface{}{"includeAllFields": true},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"comments", "posts"}, linkedCollections())
+
+	err = restoreViewProperties(ctx, db, updateResult)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"posts"}, linkedCollections())
+
+	// Properties a search-alias view has are rejected for an arangosearch view
+	_, err = updateViewPropertiesWithTracking(ctx, db, "posts_view", map[string]interface{}{
+		"indexes": []interface{}{},
+	})
+	assert.ErrorContains(t, err, "invalid options")
+
+	// Deleting a view and rolling back recreates it with its links
+	deleteResult, err := deleteViewWithTracking(ctx, db, "posts_view")
+	require.NoError(t, err)
+
+	exists, err := db.ViewExists(ctx, "posts_view")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	err = restoreView(ctx, db, deleteResult)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"posts"}, linkedCollections())
+
+	// Rolling back the creation removes the view, then the analyzer can be deleted
+	err = deleteView(ctx, db, viewResult.Name)
+	require.NoError(t, err)
+
+	deleteAnalyzerResult, err := deleteAnalyzerWithTracking(ctx, db, "text_en_no_stem", map[string]interface{}{})
+	require.NoError(t, err)
+
+	_, err = db.Analyzer(ctx, "text_en_no_stem")
+	assert.Error(t, err)
+
+	err = restoreAnalyzer(ctx, db, deleteAnalyzerResult)
+	require.NoError(t, err)
+
+	err = deleteAnalyzer(ctx, db, analyzerResult.Name)
+	require.NoError(t, err)
+
+	_, err = db.Analyzer(ctx, "text_en_no_stem")
+	assert.Error(t, err)
+}
+
+func TestSetCollectionSchema(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_set_collection_schema")
+
+	err := createCollection(ctx, db, "test_collection", map[string]interface{}{
+		"type": "document",
+	})
+	require.NoError(t, err)
+
+	result, err := setCollectionSchemaWithTracking(ctx, db, "test_collection", map[string]interface{}{
+		"rule": map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"email": map[string]interface{}{"type": "string"}},
+			"required":   []interface{}{"email"},
+		},
+		"message": "documents need an email address",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "setCollectionSchema", result.Type)
+
+	// Documents that don't match the schema are rejected
+	err = addDocument(ctx, db, "test_collection", map[string]interface{}{
+		"document": map[string]interface{}{"_key": "no_email"},
+	})
+	assert.ErrorContains(t, err, "documents need an email address")
+
+	// Rolling back removes the schema again
+	err = rollbackModifyCollection(ctx, db, defaultLogger, result)
+	require.NoError(t, err)
+
+	err = addDocument(ctx, db, "test_collection", map[string]interface{}{
+		"document": map[string]interface{}{"_key": "no_email"},
+	})
+	assert.NoError(t, err)
+}
+
+func TestRenameAndTruncateCollection(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_rename_truncate")
+
+	err := createCollection(ctx, db, "users_v1", map[string]interface{}{"type": "document"})
+	require.NoError(t, err)
+
+	for _, key := range []string{"alice", "bob"} {
+		err = addDocument(ctx, db, "users_v1", map[string]interface{}{
+			"document": map[string]interface{}{"_key": key},
+		})
+		require.NoError(t, err)
+	}
+
+	// Renaming needs the client
+	_, err = renameCollectionWithTracking(ctx, db, nil, "users_v1", map[string]interface{}{"newName": "users"})
+	assert.ErrorContains(t, err, "requires MigrationOptions.Client")
+
+	renameResult, err := renameCollectionWithTracking(ctx, db, container.Client, "users_v1", map[string]interface{}{"newName": "users"})
+	require.NoError(t, err)
+
+	exists, err := db.CollectionExists(ctx, "users")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	countDocuments := func(name string) int64 {
+		coll, err := db.GetCollection(ctx, name, nil)
+		require.NoError(t, err)
+		count, err := coll.Count(ctx)
+		require.NoError(t, err)
+		return count
+	}
+
+	// Truncating with a backup in the rollback data restores the documents on rollback
+	truncateResult, err := truncateCollectionWithTracking(ctx, db, "users", map[string]interface{}{"backup": "rollbackData"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), countDocuments("users"))
+
+	err = New(db, WithMigrationOptions(MigrationOptions{Client: container.Client})).autoRollback(ctx, []OperationResult{renameResult, truncateResult})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), countDocuments("users_v1"))
+
+	// Truncating with a backup collection copies the documents there
+	truncateResult, err = truncateCollectionWithTracking(ctx, db, "users_v1", map[string]interface{}{"backup": "collection"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), countDocuments("users_v1"))
+	assert.Equal(t, int64(2), countDocuments("users_v1_backup"))
+
+	err = rollbackTruncateCollection(ctx, db, truncateResult)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), countDocuments("users_v1"))
+
+	exists, err = db.CollectionExists(ctx, "users_v1_backup")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// A backup collection the truncation did not create is kept when it fails
+	err = createCollection(ctx, db, "users_v1_backup", map[string]interface{}{"type": "document"})
+	require.NoError(t, err)
+	_, err = truncateCollectionWithTracking(ctx, db, "users_v1", map[string]interface{}{"backup": "collection"})
+	assert.ErrorContains(t, err, "failed to create backup collection")
+	assert.Equal(t, int64(2), countDocuments("users_v1"))
+
+	exists, err = db.CollectionExists(ctx, "users_v1_backup")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// Renaming onto an existing collection fails and leaves both collections
+	_, err = renameCollectionWithTracking(ctx, db, container.Client, "users_v1", map[string]interface{}{"newName": "users_v1_backup"})
+	assert.Error(t, err)
+	assert.Equal(t, int64(2), countDocuments("users_v1"))
+	assert.Equal(t, int64(0), countDocuments("users_v1_backup"))
+
+	// Without a backup the truncation can't be rolled back
+	truncateResult, err = truncateCollectionWithTracking(ctx, db, "users_v1", map[string]interface{}{})
+	require.NoError(t, err)
+	err = rollbackTruncateCollection(ctx, db, truncateResult)
+	assert.ErrorContains(t, err, "cannot rollback collection truncation without a backup")
+}
+
+func TestGraphOperations(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_graph_operations")
+
+	for name, collectionType := range map[string]string{"users": "document", "posts": "document", "likes": "edge"} {
+		err := createCollection(ctx, db, name, map[string]interface{}{"type": collectionType})
+		require.NoError(t, err)
+	}
+
+	err := createGraph(ctx, db, "social", map[string]interface{}{
+		"edgeDefinitions": []interface{}{
+			map[string]interface{}{"collection": "likes", "from": []interface{}{"users"}, "to": []interface{}{"users"}},
+		},
+	})
+	require.NoError(t, err)
+
+	edgeDefinition := func() arangodb.EdgeDefinition {
+		graph, err := db.Graph(ctx, "social", nil)
+		require.NoError(t, err)
+		require.Len(t, graph.EdgeDefinitions(), 1)
+		return graph.EdgeDefinitions()[0]
+	}
+
+	replaceResult, err := replaceEdgeDefinitionWithTracking(ctx, db, "social", map[string]interface{}{
+		"collection": "likes",
+		"from":       []interface{}{"users"},
+		"to":         []interface{}{"posts"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"posts"}, edgeDefinition().To)
+
+	addResult, err := addVertexCollectionWithTracking(ctx, db, "social", map[string]interface{}{"collection": "organizations"})
+	require.NoError(t, err)
+
+	graph, err := db.Graph(ctx, "social", nil)
+	require.NoError(t, err)
+	exists, err := graph.VertexCollectionExists(ctx, "organizations")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// Rolling back restores the edge definition and removes the vertex collection
+	m := New(db)
+	err = m.autoRollback(ctx, []OperationResult{replaceResult, addResult})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"users"}, edgeDefinition().To)
+
+	exists, err = graph.VertexCollectionExists(ctx, "organizations")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// The collection created by adding it is dropped again, an existing one is kept
+	assert.Equal(t, true, addResult.Result["collectionCreated"])
+	exists, err = db.CollectionExists(ctx, "organizations")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	addResult, err = addVertexCollectionWithTracking(ctx, db, "social", map[string]interface{}{"collection": "posts"})
+	require.NoError(t, err)
+	assert.Equal(t, false, addResult.Result["collectionCreated"])
+	err = m.autoRollback(ctx, []OperationResult{addResult})
+	require.NoError(t, err)
+	exists, err = db.CollectionExists(ctx, "posts")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// Removing a vertex collection keeps the collection
+	_, err = addVertexCollectionWithTracking(ctx, db, "social", map[string]interface{}{"collection": "organizations"})
+	require.NoError(t, err)
+	removeResult, err := removeVertexCollectionWithTracking(ctx, db, "social", map[string]interface{}{"collection": "organizations"})
+	require.NoError(t, err)
+
+	exists, err = db.CollectionExists(ctx, "organizations")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	err = m.autoRollback(ctx, []OperationResult{removeResult})
+	require.NoError(t, err)
+	exists, err = graph.VertexCollectionExists(ctx, "organizations")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestImportDocuments(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_import_documents")
+
+	err := createCollection(ctx, db, "countries", map[string]interface{}{"type": "document"})
+	require.NoError(t, err)
+
+	err = addDocument(ctx, db, "countries", map[string]interface{}{
+		"document": map[string]interface{}{"_key": "de", "name": "Deutschland"},
+	})
+	require.NoError(t, err)
+
+	fsys := fstest.MapFS{
+		"countries.jsonl": {Data: []byte("{\"_key\": \"de\", \"name\": \"Germany\"}\n{\"_key\": \"fr\", \"name\": \"France\"}\n{\"_key\": \"it\", \"name\": \"Italy\"}\n")},
+	}
+
+	coll, err := db.GetCollection(ctx, "countries", nil)
+	require.NoError(t, err)
+
+	countryName := func(key string) string {
+		var document map[string]interface{}
+		_, err := coll.ReadDocument(ctx, key, &document)
+		require.NoError(t, err)
+		return document["name"].(string)
+	}
+
+	// An existing key fails the import and the documents created before are removed
+	_, err = importDocumentsWithTracking(ctx, db, defaultLogger, fsys, ".", "countries", map[string]interface{}{
+		"file":      "countries.jsonl",
+		"batchSize": float64(2),
+	})
+	assert.ErrorContains(t, err, "failed to import document")
+
+	count, err := coll.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	result, err := importDocumentsWithTracking(ctx, db, defaultLogger, fsys, ".", "countries", map[string]interface{}{
+		"file":        "countries.jsonl",
+		"onDuplicate": "replace",
+		"batchSize":   float64(2),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Result["documentsImported"])
+	assert.ElementsMatch(t, []string{"fr", "it"}, result.RollbackData["createdKeys"])
+	assert.Equal(t, "Germany", countryName("de"))
+
+	// Rolling back removes the created documents and restores the replaced one
+	err = rollbackImportDocuments(ctx, db, result)
+	require.NoError(t, err)
+
+	count, err = coll.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, "Deutschland", countryName("de"))
+
+	// Ignored documents are left as they are and not removed on rollback
+	result, err = importDocumentsWithTracking(ctx, db, defaultLogger, nil, "", "countries", map[string]interface{}{
+		"documents": []interface{}{
+			map[string]interface{}{"_key": "de", "name": "Germany"},
+			map[string]interface{}{"name": "Spain"},
+		},
+		"onDuplicate": "ignore",
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, result.Options, "documents")
+	assert.Equal(t, "Deutschland", countryName("de"))
+	assert.Equal(t, 1, result.Result["documentsCreated"])
+
+	err = rollbackImportDocuments(ctx, db, result)
+	require.NoError(t, err)
+
+	count, err = coll.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestUserOperations(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_user_operations")
+
+	err := createCollection(ctx, db, "orders", map[string]interface{}{"type": "document"})
+	require.NoError(t, err)
+
+	// User operations need the client
+	_, err = createUserWithTracking(ctx, nil, "orders-service", map[string]interface{}{})
+	assert.ErrorContains(t, err, "createUser requires MigrationOptions.Client")
+
+	userResult, err := createUserWithTracking(ctx, container.Client, "orders-service", map[string]interface{}{
+		"password": "secret",
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, userResult.Options, "password")
+
+	databaseResult, err := changeAccessWithTracking(ctx, db, container.Client, "grantDatabaseAccess", "orders-service", map[string]interface{}{
+		"grant": "ro",
+	})
+	require.NoError(t, err)
+
+	collectionResult, err := changeAccessWithTracking(ctx, db, container.Client, "grantCollectionAccess", "orders-service", map[string]interface{}{
+		"collection": "orders",
+		"grant":      "rw",
+	})
+	require.NoError(t, err)
+
+	user, err := container.Client.User(ctx, "orders-service")
+	require.NoError(t, err)
+
+	grant, err := user.GetDatabaseAccess(ctx, db.Name())
+	require.NoError(t, err)
+	assert.Equal(t, arangodb.GrantReadOnly, grant)
+
+	grant, err = user.GetCollectionAccess(ctx, db.Name(), "orders")
+	require.NoError(t, err)
+	assert.Equal(t, arangodb.GrantReadWrite, grant)
+
+	// Revoking and rolling back restores the previous access
+	revokeResult, err := changeAccessWithTracking(ctx, db, container.Client, "revokeAccess", "orders-service", map[string]interface{}{})
+	require.NoError(t, err)
+
+	grant, err = user.GetDatabaseAccess(ctx, db.Name())
+	require.NoError(t, err)
+	assert.Equal(t, arangodb.GrantNone, grant)
+
+	err = rollbackChangeAccess(ctx, container.Client, revokeResult)
+	require.NoError(t, err)
+
+	grant, err = user.GetDatabaseAccess(ctx, db.Name())
+	require.NoError(t, err)
+	assert.Equal(t, arangodb.GrantReadOnly, grant)
+
+	// An explicit "none" is restored as it was
+	err = user.SetCollectionAccess(ctx, db.Name(), "orders", arangodb.GrantNone)
+	require.NoError(t, err)
+	grantResult, err := changeAccessWithTracking(ctx, db, container.Client, "grantCollectionAccess", "orders-service", map[string]interface{}{
+		"collection": "orders",
+		"grant":      "ro",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "none", grantResult.RollbackData["previousGrant"])
+
+	err = rollbackChangeAccess(ctx, container.Client, grantResult)
+	require.NoError(t, err)
+
+	grant, err = user.GetCollectionAccess(ctx, db.Name(), "orders")
+	require.NoError(t, err)
+	assert.Equal(t, arangodb.GrantNone, grant)
+
+	// Rolling back everything removes the user again
+	err = New(db, WithMigrationOptions(MigrationOptions{Client: container.Client})).autoRollback(ctx, []OperationResult{userResult, databaseResult, collectionResult})
+	require.NoError(t, err)
+
+	exists, err := container.Client.UserExists(ctx, "orders-service")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Databases are created with the client and removed on rollback
+	databaseResult, err = createDatabaseWithTracking(ctx, container.Client, "test_user_operations_analytics", map[string]interface{}{})
+	require.NoError(t, err)
+
+	exists, err = container.Client.DatabaseExists(ctx, "test_user_operations_analytics")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = createDatabaseWithTracking(ctx, container.Client, "test_user_operations_analytics", map[string]interface{}{})
+	assert.ErrorContains(t, err, "failed to create database")
+
+	err = deleteDatabase(ctx, container.Client, databaseResult.Name)
+	require.NoError(t, err)
+
+	exists, err = container.Client.DatabaseExists(ctx, "test_user_operations_analytics")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
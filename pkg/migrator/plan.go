@@ -0,0 +1,810 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/arangodb/go-driver/v2/arangodb/shared"
+)
+
+// PlanOutcome is the predicted outcome of a planned operation.
+type PlanOutcome string
+
+const (
+	// PlanOutcomeOK means the operation is expected to succeed.
+	PlanOutcomeOK PlanOutcome = "ok"
+
+	// PlanOutcomeWarning means the operation may succeed, but not necessarily
+	// with the intended effect (e.g., an index with the same name already exists).
+	PlanOutcomeWarning PlanOutcome = "warning"
+
+	// PlanOutcomeError means the operation is expected to fail.
+	PlanOutcomeError PlanOutcome = "error"
+)
+
+// PlannedOperation is the predicted outcome of a single operation.
+type PlannedOperation struct {
+	// Index is the position of the operation in the migration's 'up' list.
+	Index int `json:"index"`
+
+	// Type is the operation type (e.g., "createCollection").
+	Type string `json:"type"`
+
+	// Name is the name of the resource being operated on.
+	Name string `json:"name"`
+
+	// Outcome is the predicted outcome of the operation.
+	Outcome PlanOutcome `json:"outcome"`
+
+	// Reason explains a warning or error outcome.
+	Reason string `json:"reason,omitempty"`
+}
+
+// PlannedMigration is the plan for a single pending migration.
+type PlannedMigration struct {
+	// MigrationNumber is the migration file name without extension (e.g., "000001").
+	MigrationNumber string `json:"migrationNumber"`
+
+	// Description is the description from the migration file.
+	Description string `json:"description"`
+
+	// Operations contains the predicted outcome of each operation in order.
+	Operations []PlannedOperation `json:"operations"`
+}
+
+// MigrationPlan describes what MigrateArangoDatabase would do with the current
+// migration folder and database.
+type MigrationPlan struct {
+	// Migrations contains the pending migrations in the order they would be applied.
+	Migrations []PlannedMigration `json:"migrations"`
+}
+
+// HasErrors reports whether any planned operation is expected to fail.
+func (p *MigrationPlan) HasErrors() bool {
+	return p.countOutcome(PlanOutcomeError) > 0
+}
+
+func (p *MigrationPlan) countOutcome(outcome PlanOutcome) int {
+	count := 0
+	for _, migration := range p.Migrations {
+		for _, operation := range migration.Operations {
+			if operation.Outcome == outcome {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Plan loads the pending migrations and checks every operation against the current
+// database state without changing the database. Operations are checked in order, and
+// the effect of earlier planned operations is taken into account, so an index on a
+// collection created earlier in the same batch is predicted to succeed.
+//
+// Plan does not create the migration collection and does not acquire the migration
+// lock. An error is returned only if the plan itself cannot be computed; predicted
+// failures are reported through the returned plan (see MigrationPlan.HasErrors).
+//
+// # Examples
+//
+//	plan, err := migrator.Plan(ctx, db, migrator.MigrationOptions{
+//		MigrationFolder:     "./migrations",
+//		MigrationCollection: "migrations",
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	if plan.HasErrors() {
+//		return fmt.Errorf("migrations would fail")
+//	}
+func Plan(ctx context.Context, db arangodb.Database, options MigrationOptions) (*MigrationPlan, error) {
+	return New(db, WithMigrationOptions(options)).Plan(ctx)
+}
+
+// Plan computes the plan of the pending migrations like the Plan function, with the
+// operation handlers registered on the Migrator.
+func (m *Migrator) Plan(ctx context.Context) (*MigrationPlan, error) {
+	db, options := m.db, m.options
+
+	var migrationColl arangodb.Collection
+
+	exists, err := db.CollectionExists(ctx, options.MigrationCollection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if migration collection exists: %w", err)
+	}
+
+	if exists {
+		migrationColl, err = db.GetCollection(ctx, options.MigrationCollection, &arangodb.GetCollectionOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get migration collection: %w", err)
+		}
+	}
+
+	pendingMigrations, err := findPendingMigrations(ctx, migrationColl, options)
+	if err != nil {
+		return nil, err
+	}
+
+	planner := newPlanner(db, options.Client, m.handlers)
+	plan := &MigrationPlan{}
+
+	for _, pendingMigration := range pendingMigrations {
+		plannedMigration := PlannedMigration{
+			MigrationNumber: pendingMigration.MigrationNumber,
+			Description:     pendingMigration.Migration.Description,
+		}
+
+		for i, operation := range pendingMigration.Migration.Up {
+			plannedOperation := PlannedOperation{
+				Index:   i,
+				Type:    operation.Type,
+				Name:    operation.Name,
+				Outcome: PlanOutcomeOK,
+			}
+
+			if pendingMigration.Migration.Transactional {
+				err := validateTransactionalOperation(operation)
+				if err != nil {
+					plannedOperation.Outcome = PlanOutcomeError
+					plannedOperation.Reason = err.Error()
+					plannedMigration.Operations = append(plannedMigration.Operations, plannedOperation)
+					continue
+				}
+			}
+
+			outcome, reason, err := planner.check(ctx, operation)
+			if err != nil {
+				return nil, fmt.Errorf("failed to plan operation %s (%s) in migration %s: %w", operation.Type, operation.Name, pendingMigration.MigrationNumber, err)
+			}
+			plannedOperation.Outcome = outcome
+			plannedOperation.Reason = reason
+
+			plannedMigration.Operations = append(plannedMigration.Operations, plannedOperation)
+		}
+
+		plan.Migrations = append(plan.Migrations, plannedMigration)
+	}
+
+	return plan, nil
+}
+
+// logPlan writes the plan to the log, one record per migration and operation.
+func logPlan(log *slog.Logger, plan *MigrationPlan) {
+	log = log.With("dryRun", true)
+
+	if len(plan.Migrations) == 0 {
+		log.Info("[DRY RUN] no pending migrations to apply")
+		return
+	}
+
+	for _, migration := range plan.Migrations {
+		log.Info("[DRY RUN] migration would be applied", "migration", migration.MigrationNumber, "description", migration.Description)
+		for _, operation := range migration.Operations {
+			attrs := []any{"migration", migration.MigrationNumber, "operation", operation.Type, "name", operation.Name}
+			switch operation.Outcome {
+			case PlanOutcomeError:
+				log.Error("[DRY RUN]   operation would fail", append(attrs, "reason", operation.Reason)...)
+			case PlanOutcomeWarning:
+				log.Warn("[DRY RUN]   operation has a warning", append(attrs, "reason", operation.Reason)...)
+			default:
+				log.Info("[DRY RUN]   operation ok", attrs...)
+			}
+		}
+	}
+
+	log.Info("[DRY RUN] migrations would be applied",
+		"count", len(plan.Migrations), "errors", plan.countOutcome(PlanOutcomeError), "warnings", plan.countOutcome(PlanOutcomeWarning))
+}
+
+// planner checks operations against the database, overlaid with the changes made
+// by operations planned earlier.
+type planner struct {
+	db       arangodb.Database
+	client   arangodb.Client
+	handlers map[string]OperationHandler
+
+	collections map[string]bool
+	indexes     map[string]bool
+	graphs      map[string]bool
+	documents   map[string]bool
+	views       map[string]bool
+	analyzers   map[string]bool
+	users       map[string]bool
+	databases   map[string]bool
+
+	// indexSources and documentSources map collections changed by planned operations
+	// to the collection in the database that has their indexes and documents, or to
+	// "" if they have none, like a collection created or truncated in the plan.
+	indexSources    map[string]string
+	documentSources map[string]string
+}
+
+func newPlanner(db arangodb.Database, client arangodb.Client, handlers map[string]OperationHandler) *planner {
+	return &planner{
+		db:          db,
+		client:      client,
+		handlers:    handlers,
+		collections: make(map[string]bool),
+		indexes:     make(map[string]bool),
+		graphs:      make(map[string]bool),
+		documents:   make(map[string]bool),
+		views:       make(map[string]bool),
+		analyzers:   make(map[string]bool),
+		users:       make(map[string]bool),
+		databases:   make(map[string]bool),
+
+		indexSources:    make(map[string]string),
+		documentSources: make(map[string]string),
+	}
+}
+
+// check predicts the outcome of a single operation and records its effect on the
+// planned state. The returned error is reserved for failures to query the database.
+func (p *planner) check(ctx context.Context, operation Operation) (PlanOutcome, string, error) {
+	if operation.Type == goMigrationOperationType {
+		return PlanOutcomeWarning, "Go migrations can't be checked in advance", nil
+	}
+
+	handler, ok := p.handlers[operation.Type]
+	if !ok {
+		return PlanOutcomeError, fmt.Sprintf("unsupported operation type: %s", operation.Type), nil
+	}
+
+	err := handler.Validate(operation)
+	if err != nil {
+		return PlanOutcomeError, err.Error(), nil
+	}
+
+	// Only the effect of built-in operations on the database is known
+	if _, builtin := handler.(*builtinHandler); !builtin {
+		return PlanOutcomeWarning, fmt.Sprintf("operations of type %s are validated but can't be checked against the database", operation.Type), nil
+	}
+
+	options := operation.Options
+
+	switch operation.Type {
+	case "createCollection":
+		exists, err := p.collectionExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if exists {
+			return PlanOutcomeError, fmt.Sprintf("collection '%s' already exists", operation.Name), nil
+		}
+		p.collections[operation.Name] = true
+		p.moveContents(operation.Name, "", true, true)
+
+	case "deleteCollection":
+		exists, err := p.collectionExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			return PlanOutcomeError, fmt.Sprintf("collection '%s' does not exist", operation.Name), nil
+		}
+		p.collections[operation.Name] = false
+		p.moveContents(operation.Name, "", true, true)
+
+	case "renameCollection":
+		if p.client == nil {
+			return PlanOutcomeError, fmt.Sprintf("%s requires MigrationOptions.Client", operation.Type), nil
+		}
+		newName, ok := options["newName"].(string)
+		if !ok {
+			return PlanOutcomeError, "newName option missing or not a string", nil
+		}
+		exists, err := p.collectionExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			return PlanOutcomeError, fmt.Sprintf("collection '%s' does not exist", operation.Name), nil
+		}
+		exists, err = p.collectionExists(ctx, newName)
+		if err != nil {
+			return "", "", err
+		}
+		if exists {
+			return PlanOutcomeError, fmt.Sprintf("collection '%s' already exists", newName), nil
+		}
+		p.collections[operation.Name] = false
+		p.collections[newName] = true
+		p.moveContents(newName, operation.Name, true, true)
+		p.moveContents(operation.Name, "", true, true)
+
+	case "truncateCollection":
+		exists, err := p.collectionExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			return PlanOutcomeError, fmt.Sprintf("collection '%s' does not exist", operation.Name), nil
+		}
+		_, backupCollection, _ := truncateBackup(operation.Name, options)
+		if backupCollection != "" {
+			exists, err := p.collectionExists(ctx, backupCollection)
+			if err != nil {
+				return "", "", err
+			}
+			if exists {
+				return PlanOutcomeError, fmt.Sprintf("backup collection '%s' already exists", backupCollection), nil
+			}
+			p.collections[backupCollection] = true
+			p.moveContents(backupCollection, "", true, false)
+			p.moveContents(backupCollection, operation.Name, false, true)
+		}
+		p.moveContents(operation.Name, "", false, true)
+
+	case "modifyCollection", "setCollectionSchema", "importDocuments":
+		exists, err := p.collectionExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			return PlanOutcomeError, fmt.Sprintf("collection '%s' does not exist", operation.Name), nil
+		}
+
+	case "createPersistentIndex", "createGeoIndex", "createTTLIndex", "createInvertedIndex", "createMDIIndex", "createVectorIndex", "createFulltextIndex":
+		if (operation.Type == "createVectorIndex" || operation.Type == "createFulltextIndex") && p.client == nil {
+			return PlanOutcomeError, fmt.Sprintf("%s requires MigrationOptions.Client", operation.Type), nil
+		}
+		collName, ok := options["collection"].(string)
+		if !ok {
+			return PlanOutcomeError, "collection name missing or not a string", nil
+		}
+		exists, err := p.collectionExists(ctx, collName)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			return PlanOutcomeError, fmt.Sprintf("collection '%s' does not exist", collName), nil
+		}
+		indexExists, err := p.indexExists(ctx, collName, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if indexExists {
+			return PlanOutcomeWarning, fmt.Sprintf("index '%s' already exists on collection '%s' and is only reused if its definition matches", operation.Name, collName), nil
+		}
+		p.indexes[collName+"/"+operation.Name] = true
+
+	case "deleteIndex":
+		collName, ok := options["collection"].(string)
+		if !ok {
+			return PlanOutcomeError, "collection name missing or not a string", nil
+		}
+		exists, err := p.collectionExists(ctx, collName)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			return PlanOutcomeError, fmt.Sprintf("collection '%s' does not exist", collName), nil
+		}
+		indexExists, err := p.indexExists(ctx, collName, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if !indexExists {
+			return PlanOutcomeError, fmt.Sprintf("index '%s' does not exist on collection '%s'", operation.Name, collName), nil
+		}
+		p.indexes[collName+"/"+operation.Name] = false
+
+	case "createGraph":
+		definition, _, err := graphDefinition(options)
+		if err != nil {
+			return PlanOutcomeError, err.Error(), nil
+		}
+		exists, err := p.graphExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if exists {
+			return PlanOutcomeError, fmt.Sprintf("graph '%s' already exists", operation.Name), nil
+		}
+		p.graphs[operation.Name] = true
+		// Collections referenced by a new graph are created automatically
+		for _, edgeDefinition := range definition.EdgeDefinitions {
+			p.collections[edgeDefinition.Collection] = true
+			for _, vertex := range append(edgeDefinition.From, edgeDefinition.To...) {
+				p.collections[vertex] = true
+			}
+		}
+
+	case "addEdgeDefinition", "replaceEdgeDefinition", "deleteEdgeDefinition", "addVertexCollection", "removeVertexCollection":
+		exists, err := p.graphExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			return PlanOutcomeError, fmt.Sprintf("graph '%s' does not exist", operation.Name), nil
+		}
+		if operation.Type == "addVertexCollection" {
+			collection, ok := options["collection"].(string)
+			if !ok {
+				return PlanOutcomeError, "collection option missing or not a string", nil
+			}
+			p.collections[collection] = true
+		}
+
+	case "addDocument":
+		document, ok := options["document"].(map[string]interface{})
+		if !ok {
+			return PlanOutcomeError, "document field missing or not an object", nil
+		}
+		exists, err := p.collectionExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			return PlanOutcomeError, fmt.Sprintf("collection '%s' does not exist", operation.Name), nil
+		}
+		if key, ok := document["_key"].(string); ok {
+			docExists, err := p.documentExists(ctx, operation.Name, key)
+			if err != nil {
+				return "", "", err
+			}
+			if docExists {
+				return PlanOutcomeError, fmt.Sprintf("document '%s' already exists in collection '%s'", key, operation.Name), nil
+			}
+			p.documents[operation.Name+"/"+key] = true
+		}
+
+	case "updateDocument", "deleteDocument":
+		key, ok := options["_key"].(string)
+		if !ok {
+			return PlanOutcomeError, "document key missing or not a string", nil
+		}
+		exists, err := p.collectionExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			return PlanOutcomeError, fmt.Sprintf("collection '%s' does not exist", operation.Name), nil
+		}
+		docExists, err := p.documentExists(ctx, operation.Name, key)
+		if err != nil {
+			return "", "", err
+		}
+		if !docExists {
+			return PlanOutcomeError, fmt.Sprintf("document '%s' does not exist in collection '%s'", key, operation.Name), nil
+		}
+		if operation.Type == "deleteDocument" {
+			p.documents[operation.Name+"/"+key] = false
+		}
+
+	case "executeAQL":
+		// Only the syntax can be checked, the collections a query uses may not exist yet
+		for _, key := range []string{"query", "rollbackQuery"} {
+			query, ok := options[key].(string)
+			if !ok {
+				continue
+			}
+			err := p.db.ValidateQuery(ctx, query)
+			if err != nil {
+				return PlanOutcomeError, fmt.Sprintf("%s is invalid: %v", key, err), nil
+			}
+		}
+
+	case "createDatabase":
+		if p.client == nil {
+			return PlanOutcomeError, fmt.Sprintf("%s requires MigrationOptions.Client", operation.Type), nil
+		}
+		exists, err := p.databaseExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if exists {
+			return PlanOutcomeError, fmt.Sprintf("database '%s' already exists", operation.Name), nil
+		}
+		p.databases[operation.Name] = true
+
+	case "createUser":
+		if p.client == nil {
+			return PlanOutcomeError, fmt.Sprintf("%s requires MigrationOptions.Client", operation.Type), nil
+		}
+		exists, err := p.userExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if exists {
+			return PlanOutcomeError, fmt.Sprintf("user '%s' already exists", operation.Name), nil
+		}
+		p.users[operation.Name] = true
+
+	case "grantDatabaseAccess", "grantCollectionAccess", "revokeAccess":
+		if p.client == nil {
+			return PlanOutcomeError, fmt.Sprintf("%s requires MigrationOptions.Client", operation.Type), nil
+		}
+		exists, err := p.userExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			return PlanOutcomeError, fmt.Sprintf("user '%s' does not exist", operation.Name), nil
+		}
+		database, collection := accessTarget(p.db, options)
+		if collection != "" && database == p.db.Name() {
+			exists, err := p.collectionExists(ctx, collection)
+			if err != nil {
+				return "", "", err
+			}
+			if !exists {
+				return PlanOutcomeError, fmt.Sprintf("collection '%s' does not exist", collection), nil
+			}
+		}
+
+	case "createAnalyzer", "deleteAnalyzer":
+		exists, err := p.analyzerExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if operation.Type == "createAnalyzer" && exists {
+			return PlanOutcomeError, fmt.Sprintf("analyzer '%s' already exists", operation.Name), nil
+		}
+		if operation.Type == "deleteAnalyzer" && !exists {
+			return PlanOutcomeError, fmt.Sprintf("analyzer '%s' does not exist", operation.Name), nil
+		}
+		p.analyzers[operation.Name] = operation.Type == "createAnalyzer"
+
+	case "createArangoSearchView", "createSearchAliasView":
+		exists, err := p.viewExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if exists {
+			return PlanOutcomeError, fmt.Sprintf("view '%s' already exists", operation.Name), nil
+		}
+		for _, collName := range viewCollections(options) {
+			exists, err := p.collectionExists(ctx, collName)
+			if err != nil {
+				return "", "", err
+			}
+			if !exists {
+				return PlanOutcomeError, fmt.Sprintf("collection '%s' does not exist", collName), nil
+			}
+		}
+		p.views[operation.Name] = true
+
+	case "updateViewProperties", "deleteView":
+		exists, err := p.viewExists(ctx, operation.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			return PlanOutcomeError, fmt.Sprintf("view '%s' does not exist", operation.Name), nil
+		}
+		if operation.Type == "deleteView" {
+			p.views[operation.Name] = false
+		}
+
+	}
+
+	return PlanOutcomeOK, "", nil
+}
+
+// moveContents gives collection to the indexes and documents of collection from,
+// including the changes planned for them, or no indexes and documents if from is
+// empty.
+func (p *planner) moveContents(to string, from string, indexes bool, documents bool) {
+	if indexes {
+		moveOverlay(p.indexes, p.indexSources, to, from)
+	}
+	if documents {
+		moveOverlay(p.documents, p.documentSources, to, from)
+	}
+}
+
+// moveOverlay replaces the entries of collection to in an overlay keyed by
+// "collection/name" with those of collection from, and records where the entries
+// of from that aren't in the overlay are found.
+func moveOverlay(overlay map[string]bool, sources map[string]string, to string, from string) {
+	moved := make(map[string]bool)
+	for key, exists := range overlay {
+		if name, ok := strings.CutPrefix(key, from+"/"); ok && from != "" {
+			moved[to+"/"+name] = exists
+		}
+		if strings.HasPrefix(key, to+"/") {
+			delete(overlay, key)
+		}
+	}
+	for key, exists := range moved {
+		overlay[key] = exists
+	}
+
+	source := from
+	if previous, ok := sources[from]; ok {
+		source = previous
+	}
+	sources[to] = source
+}
+
+func (p *planner) collectionExists(ctx context.Context, name string) (bool, error) {
+	if exists, ok := p.collections[name]; ok {
+		return exists, nil
+	}
+
+	exists, err := p.db.CollectionExists(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if collection '%s' exists: %w", name, err)
+	}
+
+	p.collections[name] = exists
+	return exists, nil
+}
+
+func (p *planner) indexExists(ctx context.Context, collName string, name string) (bool, error) {
+	if exists, ok := p.indexes[collName+"/"+name]; ok {
+		return exists, nil
+	}
+
+	// A collection created earlier in the plan has no indexes yet, and a renamed
+	// one has those of the collection it was renamed from
+	source, changed := p.indexSources[collName]
+	if !changed {
+		source = collName
+	}
+	exists, err := p.sourceIndexExists(ctx, source, name)
+	if err != nil {
+		return false, err
+	}
+
+	p.indexes[collName+"/"+name] = exists
+	return exists, nil
+}
+
+// sourceIndexExists checks if an index exists on a collection in the database.
+func (p *planner) sourceIndexExists(ctx context.Context, collName string, name string) (bool, error) {
+	if collName == "" {
+		return false, nil
+	}
+
+	if _, planned := p.collections[collName]; planned {
+		exists, err := p.db.CollectionExists(ctx, collName)
+		if err != nil {
+			return false, fmt.Errorf("failed to check if collection '%s' exists: %w", collName, err)
+		}
+		if !exists {
+			return false, nil
+		}
+	}
+
+	coll, err := p.db.GetCollection(ctx, collName, &arangodb.GetCollectionOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get collection '%s': %w", collName, err)
+	}
+
+	indexes, err := coll.Indexes(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to list indexes of collection '%s': %w", collName, err)
+	}
+
+	for _, index := range indexes {
+		if index.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *planner) graphExists(ctx context.Context, name string) (bool, error) {
+	if exists, ok := p.graphs[name]; ok {
+		return exists, nil
+	}
+
+	exists, err := p.db.GraphExists(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if graph '%s' exists: %w", name, err)
+	}
+
+	p.graphs[name] = exists
+	return exists, nil
+}
+
+func (p *planner) viewExists(ctx context.Context, name string) (bool, error) {
+	if exists, ok := p.views[name]; ok {
+		return exists, nil
+	}
+
+	exists, err := p.db.ViewExists(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if view '%s' exists: %w", name, err)
+	}
+
+	p.views[name] = exists
+	return exists, nil
+}
+
+func (p *planner) userExists(ctx context.Context, name string) (bool, error) {
+	if exists, ok := p.users[name]; ok {
+		return exists, nil
+	}
+
+	exists, err := p.client.UserExists(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if user '%s' exists: %w", name, err)
+	}
+
+	p.users[name] = exists
+	return exists, nil
+}
+
+func (p *planner) databaseExists(ctx context.Context, name string) (bool, error) {
+	if exists, ok := p.databases[name]; ok {
+		return exists, nil
+	}
+
+	exists, err := p.client.DatabaseExists(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if database '%s' exists: %w", name, err)
+	}
+
+	p.databases[name] = exists
+	return exists, nil
+}
+
+func (p *planner) analyzerExists(ctx context.Context, name string) (bool, error) {
+	if exists, ok := p.analyzers[name]; ok {
+		return exists, nil
+	}
+
+	_, err := p.db.Analyzer(ctx, name)
+	if err != nil {
+		if shared.IsNotFound(err) {
+			p.analyzers[name] = false
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check if analyzer '%s' exists: %w", name, err)
+	}
+
+	p.analyzers[name] = true
+	return true, nil
+}
+
+func (p *planner) documentExists(ctx context.Context, collName string, key string) (bool, error) {
+	if exists, ok := p.documents[collName+"/"+key]; ok {
+		return exists, nil
+	}
+
+	// A collection created or truncated earlier in the plan has no documents yet,
+	// and a renamed one has those of the collection it was renamed from
+	source, changed := p.documentSources[collName]
+	if !changed {
+		source = collName
+	}
+	exists, err := p.sourceDocumentExists(ctx, source, key)
+	if err != nil {
+		return false, err
+	}
+
+	p.documents[collName+"/"+key] = exists
+	return exists, nil
+}
+
+// sourceDocumentExists checks if a document exists in a collection in the database.
+func (p *planner) sourceDocumentExists(ctx context.Context, collName string, key string) (bool, error) {
+	if collName == "" {
+		return false, nil
+	}
+
+	exists, err := p.db.CollectionExists(ctx, collName)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if collection '%s' exists: %w", collName, err)
+	}
+	if !exists {
+		return false, nil
+	}
+
+	coll, err := p.db.GetCollection(ctx, collName, &arangodb.GetCollectionOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get collection '%s': %w", collName, err)
+	}
+
+	exists, err = coll.DocumentExists(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if document '%s' exists: %w", key, err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,259 @@
+package migrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FramnkRulez/go-arangodb-migrator/pkg/migrator/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrationPlanHasErrors tests that only error outcomes make a plan fail
+func TestMigrationPlanHasErrors(t *testing.T) {
+	plan := &MigrationPlan{
+		Migrations: []PlannedMigration{
+			{
+				MigrationNumber: "000001",
+				Operations: []PlannedOperation{
+					{Type: "createCollection", Outcome: PlanOutcomeOK},
+					{Type: "createPersistentIndex", Outcome: PlanOutcomeWarning},
+				},
+			},
+		},
+	}
+	assert.False(t, plan.HasErrors())
+
+	plan.Migrations = append(plan.Migrations, PlannedMigration{
+		MigrationNumber: "000002",
+		Operations: []PlannedOperation{
+			{Type: "deleteCollection", Outcome: PlanOutcomeError},
+		},
+	})
+	assert.True(t, plan.HasErrors())
+}
+
+// TestPlannerOptionTypes tests that options of the wrong type are planned as errors
+func TestPlannerOptionTypes(t *testing.T) {
+	// Without option checks, the planner sees the options as they are in the file
+	unchecked := &builtinHandler{operation: builtinOperation{}}
+	p := newPlanner(nil, nil, map[string]OperationHandler{
+		"createPersistentIndex": unchecked,
+		"deleteIndex":           unchecked,
+		"updateDocument":        unchecked,
+	})
+
+	outcome, reason, err := p.check(context.Background(), Operation{Type: "createPersistentIndex", Name: "idx_email", Options: map[string]interface{}{"collection": 1.0}})
+	require.NoError(t, err)
+	assert.Equal(t, PlanOutcomeError, outcome)
+	assert.Equal(t, "collection name missing or not a string", reason)
+
+	outcome, reason, err = p.check(context.Background(), Operation{Type: "deleteIndex", Name: "idx_email", Options: map[string]interface{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, PlanOutcomeError, outcome)
+	assert.Equal(t, "collection name missing or not a string", reason)
+
+	outcome, reason, err = p.check(context.Background(), Operation{Type: "updateDocument", Name: "users", Options: map[string]interface{}{"_key": true}})
+	require.NoError(t, err)
+	assert.Equal(t, PlanOutcomeError, outcome)
+	assert.Equal(t, "document key missing or not a string", reason)
+}
+
+func TestPlan(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_plan")
+
+	tempDir := t.TempDir()
+	options := MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+	}
+
+	migration1 := `{
+		"description": "Create users",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "users",
+				"options": {
+					"type": "document"
+				}
+			},
+			{
+				"type": "createPersistentIndex",
+				"name": "idx_email",
+				"options": {
+					"collection": "users",
+					"fields": ["email"],
+					"unique": true
+				}
+			},
+			{
+				"type": "addDocument",
+				"name": "users",
+				"options": {
+					"document": {
+						"_key": "admin",
+						"email": "admin@example.com"
+					}
+				}
+			}
+		]
+	}`
+
+	err := os.WriteFile(filepath.Join(tempDir, "000001_users.json"), []byte(migration1), 0644)
+	require.NoError(t, err)
+
+	t.Run("operations on planned resources succeed", func(t *testing.T) {
+		plan, err := Plan(ctx, db, options)
+		require.NoError(t, err)
+		require.Len(t, plan.Migrations, 1)
+		assert.False(t, plan.HasErrors())
+
+		planned := plan.Migrations[0]
+		assert.Equal(t, "000001_users", planned.MigrationNumber)
+		require.Len(t, planned.Operations, 3)
+		for _, operation := range planned.Operations {
+			assert.Equal(t, PlanOutcomeOK, operation.Outcome, "%s should succeed", operation.Type)
+		}
+	})
+
+	migration2 := `{
+		"description": "Broken migration",
+		"up": [
+			{
+				"type": "deleteCollection",
+				"name": "does_not_exist"
+			},
+			{
+				"type": "createCollection",
+				"name": "users",
+				"options": {
+					"type": "document"
+				}
+			},
+			{
+				"type": "renameEverything",
+				"name": "users"
+			}
+		]
+	}`
+
+	err = os.WriteFile(filepath.Join(tempDir, "000002_broken.json"), []byte(migration2), 0644)
+	require.NoError(t, err)
+
+	t.Run("failing operations are detected", func(t *testing.T) {
+		plan, err := Plan(ctx, db, options)
+		require.NoError(t, err)
+		require.Len(t, plan.Migrations, 2)
+		assert.True(t, plan.HasErrors())
+
+		operations := plan.Migrations[1].Operations
+		require.Len(t, operations, 3)
+		assert.Equal(t, PlanOutcomeError, operations[0].Outcome)
+		assert.Contains(t, operations[0].Reason, "does not exist")
+		assert.Equal(t, PlanOutcomeError, operations[1].Outcome)
+		assert.Contains(t, operations[1].Reason, "already exists")
+		assert.Equal(t, PlanOutcomeError, operations[2].Outcome)
+		assert.Contains(t, operations[2].Reason, "unsupported operation type")
+	})
+
+	t.Run("dry run does not change the database", func(t *testing.T) {
+		err := MigrateArangoDatabase(ctx, db, MigrationOptions{
+			MigrationFolder:     tempDir,
+			MigrationCollection: "migrations",
+			DryRun:              true,
+		})
+		require.Error(t, err)
+
+		for _, collection := range []string{"users", "migrations", "migrations_lock"} {
+			exists, err := db.CollectionExists(ctx, collection)
+			require.NoError(t, err)
+			assert.False(t, exists, "collection %s should not have been created", collection)
+		}
+	})
+
+	err = os.Remove(filepath.Join(tempDir, "000002_broken.json"))
+	require.NoError(t, err)
+
+	t.Run("applied migrations are not planned", func(t *testing.T) {
+		err := MigrateArangoDatabase(ctx, db, options)
+		require.NoError(t, err)
+
+		plan, err := Plan(ctx, db, options)
+		require.NoError(t, err)
+		assert.Empty(t, plan.Migrations)
+	})
+}
+
+func TestPlanCollectionContents(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_plan_collection_contents")
+
+	// Both collections exist with an index and a document
+	for _, name := range []string{"orders", "customers"} {
+		err := createCollection(ctx, db, name, map[string]interface{}{"type": "document"})
+		require.NoError(t, err)
+		_, err = createPersistentIndex(ctx, db, "idx_"+name, map[string]interface{}{
+			"collection": name,
+			"fields":     []interface{}{"name"},
+		})
+		require.NoError(t, err)
+		err = addDocument(ctx, db, name, map[string]interface{}{
+			"document": map[string]interface{}{"_key": "first"},
+		})
+		require.NoError(t, err)
+	}
+
+	tempDir := t.TempDir()
+	migration := `{
+		"description": "Recreate orders, truncate and rename customers",
+		"up": [
+			{"type": "deleteCollection", "name": "orders"},
+			{"type": "createCollection", "name": "orders", "options": {"type": "document"}},
+			{"type": "createPersistentIndex", "name": "idx_orders", "options": {"collection": "orders", "fields": ["name"]}},
+			{"type": "addDocument", "name": "orders", "options": {"document": {"_key": "first"}}},
+			{"type": "truncateCollection", "name": "customers"},
+			{"type": "addDocument", "name": "customers", "options": {"document": {"_key": "first"}}},
+			{"type": "renameCollection", "name": "customers", "options": {"newName": "clients"}},
+			{"type": "deleteIndex", "name": "idx_customers", "options": {"collection": "clients"}},
+			{"type": "updateDocument", "name": "clients", "options": {"_key": "first", "name": "Jane"}},
+			{"type": "createCollection", "name": "customers", "options": {"type": "document"}},
+			{"type": "deleteDocument", "name": "customers", "options": {"_key": "first"}}
+		]
+	}`
+	err := os.WriteFile(filepath.Join(tempDir, "000001_contents.json"), []byte(migration), 0644)
+	require.NoError(t, err)
+
+	plan, err := Plan(ctx, db, MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+		Client:              container.Client,
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Migrations, 1)
+
+	operations := plan.Migrations[0].Operations
+	require.Len(t, operations, 11)
+	for _, operation := range operations[:10] {
+		assert.Equal(t, PlanOutcomeOK, operation.Outcome, "%s %s should succeed: %s", operation.Type, operation.Name, operation.Reason)
+	}
+
+	// The new customers collection has none of the documents of the old one
+	assert.Equal(t, PlanOutcomeError, operations[10].Outcome)
+	assert.Contains(t, operations[10].Reason, "document 'first' does not exist")
+}
@@ -0,0 +1,189 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// MigrationFunc is the up or down function of a Go migration.
+type MigrationFunc func(ctx context.Context, db arangodb.Database) error
+
+// goMigrationOperationType is the operation type recorded for a Go migration. It
+// can't be used in migration files.
+const goMigrationOperationType = "goMigration"
+
+// goMigration is a migration registered with Register.
+type goMigration struct {
+	number      string
+	description string
+	up          MigrationFunc
+	down        MigrationFunc
+}
+
+var (
+	goMigrationsMu sync.RWMutex
+	goMigrations   = make(map[string]*goMigration)
+)
+
+// Register adds a migration implemented in Go, for changes that can't be expressed
+// as operations in a migration file (e.g., backfilling a field computed from several
+// documents). It is meant to be called from an init function:
+//
+//	func init() {
+//		migrator.Register("000004_backfill_full_names", "Backfill full names", backfillUp, backfillDown)
+//	}
+//
+// The number works like a migration file name without extension: Go migrations are
+// ordered with file migrations by their numeric prefix, are recorded in the same
+// migration collection, run under the migration lock and are reported by Status.
+// The down function is used when the migration is rolled back and may be nil if the
+// migration can't be undone.
+//
+// Go migrations have no file, so they have no SHA256 hash and changes to their code
+// are not detected. Plan reports them with a warning since they can't be checked
+// in advance, and ValidateFolder doesn't know about them.
+//
+// Register panics if number has no numeric prefix, up is nil, or a migration with
+// the same number is already registered.
+func Register(number string, description string, up MigrationFunc, down MigrationFunc) {
+	if _, err := migrationVersion(number); err != nil {
+		panic(fmt.Sprintf("migrator: Register: %v", err))
+	}
+	if up == nil {
+		panic(fmt.Sprintf("migrator: Register: up function of migration %s is nil", number))
+	}
+
+	goMigrationsMu.Lock()
+	defer goMigrationsMu.Unlock()
+
+	if _, exists := goMigrations[number]; exists {
+		panic(fmt.Sprintf("migrator: Register: migration %s is already registered", number))
+	}
+
+	goMigrations[number] = &goMigration{
+		number:      number,
+		description: description,
+		up:          up,
+		down:        down,
+	}
+}
+
+// registeredGoMigrations returns the registered Go migrations in no particular order.
+func registeredGoMigrations() []*goMigration {
+	goMigrationsMu.RLock()
+	defer goMigrationsMu.RUnlock()
+
+	registered := make([]*goMigration, 0, len(goMigrations))
+	for _, migration := range goMigrations {
+		registered = append(registered, migration)
+	}
+	return registered
+}
+
+// lookupGoMigration returns the registered Go migration with the given number.
+func lookupGoMigration(number string) (*goMigration, bool) {
+	goMigrationsMu.RLock()
+	defer goMigrationsMu.RUnlock()
+
+	migration, ok := goMigrations[number]
+	return migration, ok
+}
+
+// migration returns a Migration with a single operation that runs the Go migration,
+// so it can be applied, tracked and rolled back like a file migration.
+func (m *goMigration) migration() *Migration {
+	return &Migration{
+		Description: m.description,
+		Up: []Operation{
+			{
+				Type: goMigrationOperationType,
+				Name: m.number,
+			},
+		},
+	}
+}
+
+// listMigrations returns the migration files in the migration folder together with
+// the registered Go migrations, ordered by numeric prefix. Without a migration folder
+// there are only Go migrations.
+func listMigrations(options MigrationOptions) ([]migrationFile, error) {
+	var migrationFiles []migrationFile
+	if fsys, dir := migrationFolder(options); fsys != nil {
+		var err error
+		migrationFiles, err = listMigrationFiles(fsys, dir, options.logger())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	numbers := make(map[string]bool)
+	for _, migrationFile := range migrationFiles {
+		numbers[migrationFile.MigrationNumber] = true
+	}
+
+	for _, registered := range registeredGoMigrations() {
+		if numbers[registered.number] {
+			return nil, fmt.Errorf("migration %s is defined by both a migration file and a registered Go migration", registered.number)
+		}
+		migrationFiles = append(migrationFiles, migrationFile{
+			MigrationNumber: registered.number,
+			GoMigration:     registered,
+		})
+	}
+
+	sort.SliceStable(migrationFiles, func(i, j int) bool {
+		return migrationNumberLess(migrationFiles[i].MigrationNumber, migrationFiles[j].MigrationNumber)
+	})
+
+	return migrationFiles, nil
+}
+
+// runGoMigration runs the up function of a registered Go migration.
+func runGoMigration(ctx context.Context, db arangodb.Database, number string) (OperationResult, error) {
+	result := OperationResult{
+		Type:    goMigrationOperationType,
+		Name:    number,
+		Options: make(map[string]interface{}),
+		Result:  make(map[string]interface{}),
+	}
+
+	migration, ok := lookupGoMigration(number)
+	if !ok {
+		return result, fmt.Errorf("no Go migration is registered as %s", number)
+	}
+
+	err := migration.up(ctx, db)
+	if err != nil {
+		return result, fmt.Errorf("failed to run Go migration %s: %w", number, err)
+	}
+
+	return result, nil
+}
+
+// checkFileOperations returns an error if a migration file uses the goMigration
+// operation type, which would run a registered Go migration from the file.
+func checkFileOperations(migrationNumber string, operations []Operation) error {
+	for _, operation := range operations {
+		if operation.Type == goMigrationOperationType {
+			return fmt.Errorf("migration file %s: %w: %s", migrationNumber, ErrUnsupportedOperation, operation.Type)
+		}
+	}
+	return nil
+}
+
+// rollbackGoMigration runs the down function of a registered Go migration.
+func rollbackGoMigration(ctx context.Context, db arangodb.Database, number string) error {
+	migration, ok := lookupGoMigration(number)
+	if !ok {
+		return fmt.Errorf("no Go migration is registered as %s", number)
+	}
+	if migration.down == nil {
+		return fmt.Errorf("cannot rollback Go migration %s without a down function", number)
+	}
+
+	return migration.down(ctx, db)
+}
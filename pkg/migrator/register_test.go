@@ -0,0 +1,253 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/FramnkRulez/go-arangodb-migrator/pkg/migrator/testutil"
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerForTest registers a Go migration and removes it again when the test ends.
+func registerForTest(t *testing.T, number string, description string, up MigrationFunc, down MigrationFunc) {
+	Register(number, description, up, down)
+	t.Cleanup(func() {
+		goMigrationsMu.Lock()
+		defer goMigrationsMu.Unlock()
+		delete(goMigrations, number)
+	})
+}
+
+func noopMigration(ctx context.Context, db arangodb.Database) error {
+	return nil
+}
+
+// TestRegisterPanics tests that invalid registrations are rejected
+func TestRegisterPanics(t *testing.T) {
+	registerForTest(t, "000001_registered", "Registered", noopMigration, nil)
+
+	assert.Panics(t, func() {
+		Register("000001_registered", "Registered twice", noopMigration, nil)
+	})
+	assert.Panics(t, func() {
+		Register("backfill", "No numeric prefix", noopMigration, nil)
+	})
+	assert.Panics(t, func() {
+		Register("000002_no_up", "No up function", nil, noopMigration)
+	})
+}
+
+// TestRegisterListMigrations tests that Go migrations are ordered with migration files
+func TestRegisterListMigrations(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"000001_first.json", "000003_third.yaml"} {
+		err := os.WriteFile(filepath.Join(tempDir, name), []byte(`{}`), 0644)
+		require.NoError(t, err)
+	}
+
+	registerForTest(t, "000002_second", "Second", noopMigration, nil)
+	registerForTest(t, "10_tenth", "Tenth", noopMigration, nil)
+
+	migrationFiles, err := listMigrations(MigrationOptions{MigrationFolder: tempDir})
+	require.NoError(t, err)
+	require.Len(t, migrationFiles, 4)
+
+	var numbers []string
+	for _, migrationFile := range migrationFiles {
+		numbers = append(numbers, migrationFile.MigrationNumber)
+	}
+	assert.Equal(t, []string{"000001_first", "000002_second", "000003_third", "10_tenth"}, numbers)
+	assert.Nil(t, migrationFiles[0].GoMigration)
+	assert.NotNil(t, migrationFiles[1].GoMigration)
+	assert.Empty(t, migrationFiles[1].Path)
+
+	// A Go migration can't share its number with a file
+	registerForTest(t, "000003_third", "Third", noopMigration, nil)
+	_, err = listMigrations(MigrationOptions{MigrationFolder: tempDir})
+	assert.Error(t, err)
+}
+
+// TestRegisterMigrationFileGoMigration tests that a migration file can't run a
+// registered Go migration
+func TestRegisterMigrationFileGoMigration(t *testing.T) {
+	registerForTest(t, "000001_registered", "Registered", noopMigration, nil)
+
+	fsys := fstest.MapFS{
+		"000002_run_go.json": &fstest.MapFile{Data: []byte(`{
+			"up": [{"type": "goMigration", "name": "000001_registered"}]
+		}`)},
+	}
+
+	_, err := readPendingMigrationFile("000002_run_go", fsys, "000002_run_go.json")
+	assert.ErrorIs(t, err, ErrUnsupportedOperation)
+	assert.ErrorContains(t, err, "000002_run_go")
+}
+
+func TestMigrateArangoDatabaseWithGoMigrations(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_go_migrations")
+
+	tempDir := t.TempDir()
+
+	migration1 := `{
+		"description": "Create users",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "users",
+				"options": {
+					"type": "document"
+				}
+			},
+			{
+				"type": "addDocument",
+				"name": "users",
+				"options": {
+					"document": {
+						"_key": "jane",
+						"firstName": "Jane",
+						"lastName": "Smith"
+					}
+				}
+			}
+		]
+	}`
+	err := os.WriteFile(filepath.Join(tempDir, "000001_users.json"), []byte(migration1), 0644)
+	require.NoError(t, err)
+
+	migration3 := `{
+		"description": "Index full names",
+		"up": [
+			{
+				"type": "createPersistentIndex",
+				"name": "idx_full_name",
+				"options": {
+					"collection": "users",
+					"fields": ["fullName"]
+				}
+			}
+		]
+	}`
+	err = os.WriteFile(filepath.Join(tempDir, "000003_full_name_index.json"), []byte(migration3), 0644)
+	require.NoError(t, err)
+
+	// Backfill a field computed from other fields, between the two file migrations
+	registerForTest(t, "000002_backfill_full_names", "Backfill full names",
+		func(ctx context.Context, db arangodb.Database) error {
+			cursor, err := db.Query(ctx, `FOR u IN users UPDATE u WITH { fullName: CONCAT(u.firstName, " ", u.lastName) } IN users`, nil)
+			if err != nil {
+				return err
+			}
+			return cursor.Close()
+		},
+		func(ctx context.Context, db arangodb.Database) error {
+			cursor, err := db.Query(ctx, `FOR u IN users UPDATE u WITH { fullName: null } IN users OPTIONS { keepNull: false }`, nil)
+			if err != nil {
+				return err
+			}
+			return cursor.Close()
+		},
+	)
+
+	options := MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+	}
+
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+
+	users, err := db.GetCollection(ctx, "users", nil)
+	require.NoError(t, err)
+
+	var user map[string]interface{}
+	_, err = users.ReadDocument(ctx, "jane", &user)
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Smith", user["fullName"])
+
+	t.Run("status", func(t *testing.T) {
+		statuses, err := Status(ctx, db, options)
+		require.NoError(t, err)
+		require.Len(t, statuses, 3)
+
+		assert.Equal(t, "000002_backfill_full_names", statuses[1].MigrationNumber)
+		assert.Equal(t, "Backfill full names", statuses[1].Description)
+		assert.Equal(t, MigrationStateApplied, statuses[1].State)
+		assert.Empty(t, statuses[1].FileSha256)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		err := RollbackArangoDatabase(ctx, db, options, "1")
+		require.NoError(t, err)
+
+		var user map[string]interface{}
+		_, err = users.ReadDocument(ctx, "jane", &user)
+		require.NoError(t, err)
+		assert.NotContains(t, user, "fullName", "Down function should have removed the field")
+
+		migrations, err := db.GetCollection(ctx, "migrations", nil)
+		require.NoError(t, err)
+
+		exists, err := migrations.DocumentExists(ctx, "000002_backfill_full_names")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestMigrateArangoDatabaseWithFailingGoMigration(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_failing_go_migration")
+
+	var downCalled bool
+	registerForTest(t, "000001_create_things", "Create things",
+		func(ctx context.Context, db arangodb.Database) error {
+			_, err := db.CreateCollection(ctx, "things", &arangodb.CreateCollectionProperties{})
+			return err
+		},
+		func(ctx context.Context, db arangodb.Database) error {
+			downCalled = true
+			coll, err := db.GetCollection(ctx, "things", nil)
+			if err != nil {
+				return err
+			}
+			return coll.Remove(ctx)
+		},
+	)
+	registerForTest(t, "000002_fail", "Fail",
+		func(ctx context.Context, db arangodb.Database) error {
+			return fmt.Errorf("backfill failed")
+		},
+		nil,
+	)
+
+	// Go migrations only, with the whole batch rolled back on failure
+	err := MigrateArangoDatabase(ctx, db, MigrationOptions{
+		MigrationCollection: "migrations",
+		AutoRollback:        true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backfill failed")
+	assert.True(t, downCalled, "Down function of the earlier Go migration should run")
+
+	exists, err := db.CollectionExists(ctx, "things")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
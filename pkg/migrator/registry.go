@@ -0,0 +1,151 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// OperationHandler implements an operation type of migration files. Every built-in
+// operation type is implemented by a handler, and custom operation types can be added
+// to a Migrator with RegisterOperation.
+type OperationHandler interface {
+	// Apply applies the operation and returns what is needed to roll it back. The
+	// result is stored with the applied migration, so Result and RollbackData must
+	// be JSON serializable. Type and Name are filled in by the Migrator, and Options
+	// unless Apply sets them, for example to leave out a password.
+	Apply(ctx context.Context, db arangodb.Database, operation Operation) (OperationResult, error)
+
+	// Rollback undoes an operation applied earlier, possibly by another process that
+	// recorded the result in the migration collection. Operations that can't be
+	// undone return an error.
+	Rollback(ctx context.Context, db arangodb.Database, operation OperationResult) error
+
+	// Validate checks an operation without a database. It is called before the
+	// operation is applied and by Plan.
+	Validate(operation Operation) error
+}
+
+// Migrator applies migrations to a database using a registry of operation handlers,
+// calling its Hooks at each step. MigrateArangoDatabase, RollbackArangoDatabase,
+// RevertArangoDatabaseMigration, Status and Plan use a Migrator with the built-in
+// operation types only and no hooks.
+type Migrator struct {
+	db       arangodb.Database
+	options  MigrationOptions
+	handlers map[string]OperationHandler
+	hooks    Hooks
+}
+
+// Option configures a Migrator created with New.
+type Option func(*Migrator)
+
+// WithMigrationOptions sets the options migrations are applied with.
+func WithMigrationOptions(options MigrationOptions) Option {
+	return func(m *Migrator) {
+		m.options = options
+	}
+}
+
+// New returns a Migrator for db with handlers for all built-in operation types.
+//
+// # Examples
+//
+//	m := migrator.New(db, migrator.WithMigrationOptions(migrator.MigrationOptions{
+//		MigrationFolder:     "./migrations",
+//		MigrationCollection: "migrations",
+//	}))
+//	err := m.RegisterOperation("publishEvent", publishEventHandler{})
+//	if err != nil {
+//		return err
+//	}
+//	err = m.Up(ctx)
+func New(db arangodb.Database, opts ...Option) *Migrator {
+	m := &Migrator{
+		db:       db,
+		handlers: make(map[string]OperationHandler, len(builtinOperations)),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	for operationType, operation := range builtinOperations {
+		m.handlers[operationType] = &builtinHandler{operation: operation, options: &m.options}
+	}
+
+	return m
+}
+
+// RegisterOperation adds a handler for a custom operation type, which can then be
+// used in migration files like the built-in types. Registering a built-in type
+// replaces its implementation.
+//
+// Plan only validates operations of custom types and reports them with a warning,
+// and ValidateFolder doesn't know about them. Custom types can't be used in
+// transactional migrations.
+func (m *Migrator) RegisterOperation(operationType string, handler OperationHandler) error {
+	if operationType == "" {
+		return fmt.Errorf("operation type is empty")
+	}
+	if operationType == goMigrationOperationType {
+		return fmt.Errorf("operation type %s is reserved for Go migrations", operationType)
+	}
+	if handler == nil {
+		return fmt.Errorf("handler of operation type %s is nil", operationType)
+	}
+
+	m.handlers[operationType] = handler
+	return nil
+}
+
+// handler returns the handler registered for an operation type.
+func (m *Migrator) handler(operationType string) (OperationHandler, error) {
+	handler, ok := m.handlers[operationType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedOperation, operationType)
+	}
+	return handler, nil
+}
+
+// applyOperation validates and applies a single migration operation with its handler
+// and returns the result needed to roll it back later.
+func (m *Migrator) applyOperation(ctx context.Context, operation Operation) (OperationResult, error) {
+	handler, err := m.handler(operation.Type)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	err = handler.Validate(operation)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	operationResult, err := handler.Apply(ctx, m.db, operation)
+	if err != nil {
+		return operationResult, err
+	}
+
+	operationResult.Type = operation.Type
+	operationResult.Name = operation.Name
+	if operationResult.Options == nil {
+		operationResult.Options = operation.Options
+	}
+	return operationResult, nil
+}
+
+// validateOperation checks that an operation in a migration file has a built-in type
+// and that its options have the types the operation expects.
+func validateOperation(operation Operation) error {
+	if operation.Name == "" {
+		return fmt.Errorf("name missing")
+	}
+
+	builtin, ok := builtinOperations[operation.Type]
+	if !ok || operation.Type == goMigrationOperationType {
+		return fmt.Errorf("%w: %s", ErrUnsupportedOperation, operation.Type)
+	}
+
+	return builtin.validateOptions(operation)
+}
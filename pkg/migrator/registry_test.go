@@ -0,0 +1,178 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FramnkRulez/go-arangodb-migrator/pkg/migrator/testutil"
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// settingHandler is a custom operation that stores a setting document in the
+// settings collection.
+type settingHandler struct {
+	applied    []string
+	rolledBack []string
+}
+
+func (h *settingHandler) Apply(ctx context.Context, db arangodb.Database, operation Operation) (OperationResult, error) {
+	h.applied = append(h.applied, operation.Name)
+	if db == nil {
+		return OperationResult{Result: map[string]interface{}{"stored": false}}, nil
+	}
+
+	coll, err := db.GetCollection(ctx, "settings", nil)
+	if err != nil {
+		return OperationResult{}, err
+	}
+	_, err = coll.CreateDocument(ctx, map[string]interface{}{"_key": operation.Name, "value": operation.Options["value"]})
+	if err != nil {
+		return OperationResult{}, err
+	}
+	return OperationResult{Result: map[string]interface{}{"stored": true}}, nil
+}
+
+func (h *settingHandler) Rollback(ctx context.Context, db arangodb.Database, operation OperationResult) error {
+	h.rolledBack = append(h.rolledBack, operation.Name)
+	if db == nil {
+		return nil
+	}
+
+	coll, err := db.GetCollection(ctx, "settings", nil)
+	if err != nil {
+		return err
+	}
+	_, err = coll.DeleteDocument(ctx, operation.Name)
+	return err
+}
+
+func (h *settingHandler) Validate(operation Operation) error {
+	if _, ok := operation.Options["value"]; !ok {
+		return fmt.Errorf("value option missing")
+	}
+	return nil
+}
+
+// secretHandler is a settingHandler that records its options without the value.
+type secretHandler struct {
+	settingHandler
+}
+
+func (h *secretHandler) Apply(ctx context.Context, db arangodb.Database, operation Operation) (OperationResult, error) {
+	result, err := h.settingHandler.Apply(ctx, db, operation)
+	result.Options = map[string]interface{}{}
+	return result, err
+}
+
+// TestRegisterOperation tests registering custom operation handlers on a Migrator
+func TestRegisterOperation(t *testing.T) {
+	m := New(nil)
+	for operationType := range builtinOperations {
+		assert.Contains(t, m.handlers, operationType)
+	}
+
+	handler := &settingHandler{}
+	assert.Error(t, m.RegisterOperation("", handler))
+	assert.Error(t, m.RegisterOperation(goMigrationOperationType, handler))
+	assert.Error(t, m.RegisterOperation("setSetting", nil))
+	require.NoError(t, m.RegisterOperation("setSetting", handler))
+
+	ctx := context.Background()
+
+	_, err := m.applyOperation(ctx, Operation{Type: "setSetting", Name: "theme"})
+	assert.ErrorContains(t, err, "value option missing")
+	assert.Empty(t, handler.applied, "invalid operations are not applied")
+
+	_, err = m.applyOperation(ctx, Operation{Type: "setSettings", Name: "theme"})
+	assert.ErrorContains(t, err, "unsupported operation type: setSettings")
+
+	options := map[string]interface{}{"value": "dark"}
+	result, err := m.applyOperation(ctx, Operation{Type: "setSetting", Name: "theme", Options: options})
+	require.NoError(t, err)
+	assert.Equal(t, OperationResult{
+		Type:    "setSetting",
+		Name:    "theme",
+		Options: options,
+		Result:  map[string]interface{}{"stored": false},
+	}, result)
+
+	err = m.autoRollback(ctx, []OperationResult{result})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"theme"}, handler.rolledBack)
+
+	// Options set by the handler are recorded instead of the operation's
+	require.NoError(t, m.RegisterOperation("setSecret", &secretHandler{}))
+	result, err = m.applyOperation(ctx, Operation{Type: "setSecret", Name: "apiKey", Options: options})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{}, result.Options)
+
+	// Migration files are validated against the built-in types only
+	assert.ErrorContains(t, validateOperation(Operation{Type: "setSetting", Name: "theme", Options: options}), "unsupported operation type")
+	assert.ErrorContains(t, validateOperation(Operation{Type: goMigrationOperationType, Name: "000001"}), "unsupported operation type")
+}
+
+func TestMigratorCustomOperation(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_custom_operation")
+
+	tempDir := t.TempDir()
+
+	migration1 := `{
+		"description": "Settings",
+		"up": [
+			{"type": "createCollection", "name": "settings", "options": {"type": "document"}},
+			{"type": "setSetting", "name": "theme", "options": {"value": "dark"}}
+		]
+	}`
+	err := os.WriteFile(filepath.Join(tempDir, "000001_settings.json"), []byte(migration1), 0644)
+	require.NoError(t, err)
+
+	handler := &settingHandler{}
+	m := New(db, WithMigrationOptions(MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+	}))
+	require.NoError(t, m.RegisterOperation("setSetting", handler))
+
+	plan, err := m.Plan(ctx)
+	require.NoError(t, err)
+	require.Len(t, plan.Migrations, 1)
+	assert.Equal(t, PlanOutcomeWarning, plan.Migrations[0].Operations[1].Outcome)
+
+	err = m.Up(ctx)
+	require.NoError(t, err)
+
+	settings, err := db.GetCollection(ctx, "settings", nil)
+	require.NoError(t, err)
+	exists, err := settings.DocumentExists(ctx, "theme")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// Reverting from the recorded results uses the custom rollback
+	err = m.Revert(ctx, "000001")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"theme"}, handler.rolledBack)
+
+	exists, err = db.CollectionExists(ctx, "settings")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Without the handler the operation type is unknown
+	err = MigrateArangoDatabase(ctx, db, MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+		AutoRollback:        true,
+	})
+	assert.ErrorContains(t, err, "unsupported operation type: setSetting")
+}
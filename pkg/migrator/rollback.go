@@ -0,0 +1,319 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// RollbackArangoDatabase rolls back every applied migration whose numeric prefix is
+// greater than target. Migrations are rolled back newest first by executing the
+// operations in their 'down' list, and each migration's record is removed from the
+// migration collection once its down operations succeed.
+//
+// The target may be given as a bare number ("2", "000002") or as a full migration
+// number ("000002_create_posts"); only its numeric prefix is used. A target of "0"
+// rolls back every applied migration.
+//
+// Migrations without a 'down' list, or whose file no longer exists, are reverted by
+// replaying the operation results recorded when they were applied (see
+// RevertArangoDatabaseMigration).
+//
+// If a down operation fails, the down operations already executed for that migration
+// are rolled back, the migration stays recorded as applied and an error is returned.
+// Migrations rolled back before the failing one remain rolled back.
+//
+// # Examples
+//
+// Roll back everything applied after migration 000002:
+//
+//	err := migrator.RollbackArangoDatabase(ctx, db, migrator.MigrationOptions{
+//		MigrationFolder:     "./migrations",
+//		MigrationCollection: "migrations",
+//	}, "000002")
+func RollbackArangoDatabase(ctx context.Context, db arangodb.Database, options MigrationOptions, target string) error {
+	return New(db, WithMigrationOptions(options)).Down(ctx, target)
+}
+
+// Down rolls back every applied migration newer than target like
+// RollbackArangoDatabase, with the operation handlers and hooks of the Migrator.
+func (m *Migrator) Down(ctx context.Context, target string) error {
+	db, options := m.db, m.options
+	log := options.logger()
+	start := time.Now()
+
+	targetVersion, err := migrationVersion(target)
+	if err != nil {
+		return fmt.Errorf("invalid rollback target: %w", err)
+	}
+
+	lock, err := acquireMigrationLock(ctx, db, options)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+	ctx = lock.ctx
+
+	migrationColl, err := getMigrationCollection(ctx, db, options.MigrationCollection)
+	if err != nil {
+		return err
+	}
+
+	appliedMigrations, err := readAppliedMigrations(ctx, db, options.MigrationCollection)
+	if err != nil {
+		return err
+	}
+
+	type rollbackCandidate struct {
+		applied AppliedMigration
+		version int
+	}
+
+	var candidates []rollbackCandidate
+	for _, appliedMigration := range appliedMigrations {
+		version, err := migrationVersion(appliedMigration.MigrationNumber)
+		if err != nil {
+			return err
+		}
+		if version > targetVersion {
+			candidates = append(candidates, rollbackCandidate{applied: appliedMigration, version: version})
+		}
+	}
+
+	if len(candidates) == 0 {
+		log.Info("no applied migrations to roll back")
+		return nil
+	}
+
+	// Roll back newest migrations first
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].version > candidates[j].version
+	})
+
+	// Without the migration folder every migration is reverted from its recorded results
+	migrationFiles, err := listMigrations(options)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	filesByNumber := make(map[string]migrationFile)
+	for _, migrationFile := range migrationFiles {
+		filesByNumber[migrationFile.MigrationNumber] = migrationFile
+	}
+
+	for _, candidate := range candidates {
+		migrationNumber := candidate.applied.MigrationNumber
+		migrationFile, exists := filesByNumber[migrationNumber]
+		fullpath := migrationFile.Path
+
+		log.Info("rolling back migration", "migration", migrationNumber)
+		migrationStart := time.Now()
+		finish := m.startMigration(ctx, MigrationEvent{MigrationNumber: migrationNumber, Direction: DirectionDown})
+
+		// The recorded results of a Go migration run its down function
+		if migrationFile.GoMigration != nil {
+			err = m.revertAppliedMigration(ctx, migrationColl, candidate.applied)
+			if err != nil {
+				return finish(err)
+			}
+			finish(nil)
+			continue
+		}
+
+		if !exists {
+			log.Warn("migration file no longer exists, reverting from recorded operation results", "migration", migrationNumber)
+			err = m.revertAppliedMigration(ctx, migrationColl, candidate.applied)
+			if err != nil {
+				return finish(err)
+			}
+			finish(nil)
+			continue
+		}
+
+		hash, err := getMigrationSHA256(migrationFile.FS, fullpath)
+		if err != nil {
+			return finish(fmt.Errorf("failed to compute hash for migration file: %w", err))
+		}
+
+		if candidate.applied.Sha256 != hash {
+			if options.Force {
+				log.Warn("migration file has been modified since last applied, but continuing due to force flag", "migration", migrationNumber)
+			} else {
+				return finish(fmt.Errorf("%w: %s (use --force to override)", ErrChecksumMismatch, migrationNumber))
+			}
+		}
+
+		migration, err := readMigrationFile(migrationFile.FS, fullpath)
+		if err != nil {
+			return finish(err)
+		}
+
+		err = checkFileOperations(migrationNumber, migration.Down)
+		if err != nil {
+			return finish(err)
+		}
+
+		if len(migration.Down) == 0 {
+			log.Info("migration file has no 'down' list, reverting from recorded operation results", "migration", migrationNumber)
+			err = m.revertAppliedMigration(ctx, migrationColl, candidate.applied)
+			if err != nil {
+				return finish(err)
+			}
+			finish(nil)
+			continue
+		}
+
+		if migration.Transactional {
+			_, err = m.applyTransactionalOperations(ctx, migrationNumber, DirectionDown, migration.Down)
+			if err != nil {
+				return finish(migrationError(migrationNumber, DirectionDown, err))
+			}
+		} else {
+			err = m.applyDownOperations(ctx, migrationNumber, migration.Down)
+			if err != nil {
+				return finish(err)
+			}
+		}
+
+		_, err = migrationColl.DeleteDocument(ctx, migrationNumber)
+		if err != nil {
+			return finish(fmt.Errorf("failed to remove applied migration record %s: %w", migrationNumber, err))
+		}
+
+		log.Info("migration rolled back successfully", "migration", migrationNumber, "duration", time.Since(migrationStart))
+		finish(nil)
+	}
+
+	log.Info("all migrations rolled back successfully", "count", len(candidates), "duration", time.Since(start))
+	return nil
+}
+
+// applyDownOperations applies the down list of a migration. If an operation fails,
+// the down operations already applied are undone so the migration stays applied.
+func (m *Migrator) applyDownOperations(ctx context.Context, migrationNumber string, operations []Operation) error {
+	var downOperations []OperationResult
+	for i, operation := range operations {
+		event := OperationEvent{MigrationNumber: migrationNumber, Direction: DirectionDown, Index: i, Operation: operation}
+		operationResult, err := m.runOperation(ctx, event, m.applyOperation)
+		if err != nil {
+			log := m.options.logger()
+			log.Error("rollback operation failed", "migration", migrationNumber, "operation", operation.Type, "name", operation.Name, "error", err)
+			log.Error("restoring operations already rolled back for current migration", "migration", migrationNumber)
+
+			restoreErr := m.autoRollback(ctx, downOperations)
+			if restoreErr != nil {
+				log.Error("database may be in an inconsistent state", "migration", migrationNumber)
+				return errors.Join(operationError(migrationNumber, DirectionDown, i, operation, err), fmt.Errorf("failed to restore migration %s after failed rollback: %w", migrationNumber, restoreErr))
+			}
+			return operationError(migrationNumber, DirectionDown, i, operation, err)
+		}
+
+		downOperations = append(downOperations, operationResult)
+	}
+
+	return nil
+}
+
+// RevertArangoDatabaseMigration reverts a single applied migration using the
+// OperationResults recorded when it was applied, without reading its migration file.
+// This allows migrations written without a 'down' list to be undone long after
+// they were applied. The operations are rolled back in reverse order, exactly as
+// AutoRollback would have done, and the migration's record is then removed.
+//
+// The migration may be given as a bare number ("3", "000003") or as a full migration
+// number ("000003_add_sample_data"). Migrations recorded before operation results were
+// tracked cannot be reverted this way.
+//
+// # Examples
+//
+//	err := migrator.RevertArangoDatabaseMigration(ctx, db, migrator.MigrationOptions{
+//		MigrationCollection: "migrations",
+//	}, "000003")
+func RevertArangoDatabaseMigration(ctx context.Context, db arangodb.Database, options MigrationOptions, migrationNumber string) error {
+	return New(db, WithMigrationOptions(options)).Revert(ctx, migrationNumber)
+}
+
+// Revert reverts a single applied migration like RevertArangoDatabaseMigration, with
+// the operation handlers and hooks of the Migrator.
+func (m *Migrator) Revert(ctx context.Context, migrationNumber string) error {
+	db, options := m.db, m.options
+	log := options.logger()
+
+	version, err := migrationVersion(migrationNumber)
+	if err != nil {
+		return fmt.Errorf("invalid migration to revert: %w", err)
+	}
+
+	lock, err := acquireMigrationLock(ctx, db, options)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+	ctx = lock.ctx
+
+	migrationColl, err := getMigrationCollection(ctx, db, options.MigrationCollection)
+	if err != nil {
+		return err
+	}
+
+	appliedMigrations, err := readAppliedMigrations(ctx, db, options.MigrationCollection)
+	if err != nil {
+		return err
+	}
+
+	var target *AppliedMigration
+	var newer []string
+	for i, appliedMigration := range appliedMigrations {
+		appliedVersion, err := migrationVersion(appliedMigration.MigrationNumber)
+		if err != nil {
+			return err
+		}
+		if appliedVersion == version {
+			target = &appliedMigrations[i]
+		} else if appliedVersion > version {
+			newer = append(newer, appliedMigration.MigrationNumber)
+		}
+	}
+
+	if target == nil {
+		return fmt.Errorf("migration %s has not been applied", migrationNumber)
+	}
+
+	if len(newer) > 0 {
+		log.Warn("reverting migration while newer migrations are applied", "migration", target.MigrationNumber, "newer", strings.Join(newer, ", "))
+	}
+
+	log.Info("reverting migration", "migration", target.MigrationNumber)
+	finish := m.startMigration(ctx, MigrationEvent{MigrationNumber: target.MigrationNumber, Direction: DirectionDown})
+	return finish(m.revertAppliedMigration(ctx, migrationColl, *target))
+}
+
+// revertAppliedMigration replays autoRollback over the operation results recorded for
+// an applied migration and removes its record from the migration collection.
+func (m *Migrator) revertAppliedMigration(ctx context.Context, migrationColl arangodb.Collection, appliedMigration AppliedMigration) error {
+	migrationNumber := appliedMigration.MigrationNumber
+
+	if len(appliedMigration.OperationResults) == 0 {
+		return fmt.Errorf("migration %s has no recorded operation results to revert", migrationNumber)
+	}
+
+	err := m.autoRollback(ctx, appliedMigration.OperationResults)
+	if err != nil {
+		m.options.logger().Error("database may be in an inconsistent state", "migration", migrationNumber)
+		return fmt.Errorf("failed to revert migration %s: %w", migrationNumber, err)
+	}
+
+	_, err = migrationColl.DeleteDocument(ctx, migrationNumber)
+	if err != nil {
+		return fmt.Errorf("failed to remove applied migration record %s: %w", migrationNumber, err)
+	}
+
+	m.options.logger().Info("migration rolled back successfully", "migration", migrationNumber)
+	return nil
+}
@@ -0,0 +1,290 @@
+package migrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FramnkRulez/go-arangodb-migrator/pkg/migrator/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackArangoDatabase(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_rollback")
+
+	tempDir := t.TempDir()
+
+	firstMigration := `{
+		"description": "Create users collection",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "users",
+				"options": {
+					"type": "document"
+				}
+			}
+		],
+		"down": [
+			{
+				"type": "deleteCollection",
+				"name": "users"
+			}
+		]
+	}`
+
+	secondMigration := `{
+		"description": "Create posts collection with index",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "posts",
+				"options": {
+					"type": "document"
+				}
+			},
+			{
+				"type": "createPersistentIndex",
+				"name": "idx_posts_slug",
+				"options": {
+					"collection": "posts",
+					"fields": ["slug"],
+					"unique": true
+				}
+			}
+		],
+		"down": [
+			{
+				"type": "deleteIndex",
+				"name": "idx_posts_slug",
+				"options": {
+					"collection": "posts"
+				}
+			},
+			{
+				"type": "deleteCollection",
+				"name": "posts"
+			}
+		]
+	}`
+
+	err := os.WriteFile(filepath.Join(tempDir, "000001_users.json"), []byte(firstMigration), 0644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "000002_posts.json"), []byte(secondMigration), 0644)
+	require.NoError(t, err)
+
+	options := MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+	}
+
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+
+	migrationsColl, err := db.GetCollection(ctx, "migrations", nil)
+	require.NoError(t, err)
+
+	t.Run("rollback to target", func(t *testing.T) {
+		err := RollbackArangoDatabase(ctx, db, options, "000001")
+		require.NoError(t, err)
+
+		exists, err := db.CollectionExists(ctx, "posts")
+		require.NoError(t, err)
+		assert.False(t, exists, "Collection posts should have been rolled back")
+
+		exists, err = db.CollectionExists(ctx, "users")
+		require.NoError(t, err)
+		assert.True(t, exists, "Collection users should be untouched")
+
+		exists, err = migrationsColl.DocumentExists(ctx, "000002_posts")
+		require.NoError(t, err)
+		assert.False(t, exists, "Migration 000002_posts should no longer be recorded")
+
+		exists, err = migrationsColl.DocumentExists(ctx, "000001_users")
+		require.NoError(t, err)
+		assert.True(t, exists, "Migration 000001_users should still be recorded")
+	})
+
+	t.Run("reapply after rollback", func(t *testing.T) {
+		err := MigrateArangoDatabase(ctx, db, options)
+		require.NoError(t, err)
+
+		exists, err := db.CollectionExists(ctx, "posts")
+		require.NoError(t, err)
+		assert.True(t, exists, "Collection posts should be recreated")
+	})
+
+	t.Run("rollback everything", func(t *testing.T) {
+		err := RollbackArangoDatabase(ctx, db, options, "0")
+		require.NoError(t, err)
+
+		for _, collectionName := range []string{"users", "posts"} {
+			exists, err := db.CollectionExists(ctx, collectionName)
+			require.NoError(t, err)
+			assert.False(t, exists, "Collection %s should have been rolled back", collectionName)
+		}
+
+		count, err := migrationsColl.Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count, "No migrations should be recorded after full rollback")
+	})
+}
+
+func TestRollbackArangoDatabaseWithoutDownList(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_rollback_no_down")
+
+	tempDir := t.TempDir()
+	migration := `{
+		"description": "Migration without down list",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "test_collection",
+				"options": {
+					"type": "document"
+				}
+			}
+		]
+	}`
+
+	err := os.WriteFile(filepath.Join(tempDir, "000001_no_down.json"), []byte(migration), 0644)
+	require.NoError(t, err)
+
+	options := MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+	}
+
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+
+	// Without a down list the recorded operation results are replayed
+	err = RollbackArangoDatabase(ctx, db, options, "0")
+	require.NoError(t, err)
+
+	exists, err := db.CollectionExists(ctx, "test_collection")
+	require.NoError(t, err)
+	assert.False(t, exists, "Collection should have been rolled back")
+
+	migrationsColl, err := db.GetCollection(ctx, "migrations", nil)
+	require.NoError(t, err)
+
+	exists, err = migrationsColl.DocumentExists(ctx, "000001_no_down")
+	require.NoError(t, err)
+	assert.False(t, exists, "Migration should no longer be recorded")
+}
+
+func TestRevertArangoDatabaseMigration(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_revert_migration")
+
+	tempDir := t.TempDir()
+	firstMigration := `{
+		"description": "Create users collection",
+		"up": [
+			{
+				"type": "createCollection",
+				"name": "users",
+				"options": {
+					"type": "document"
+				}
+			}
+		]
+	}`
+
+	secondMigration := `{
+		"description": "Seed users",
+		"up": [
+			{
+				"type": "addDocument",
+				"name": "users",
+				"options": {
+					"document": {
+						"_key": "admin",
+						"role": "admin"
+					}
+				}
+			},
+			{
+				"type": "createPersistentIndex",
+				"name": "idx_users_role",
+				"options": {
+					"collection": "users",
+					"fields": ["role"]
+				}
+			}
+		]
+	}`
+
+	err := os.WriteFile(filepath.Join(tempDir, "000001_users.json"), []byte(firstMigration), 0644)
+	require.NoError(t, err)
+	secondFile := filepath.Join(tempDir, "000002_seed_users.json")
+	err = os.WriteFile(secondFile, []byte(secondMigration), 0644)
+	require.NoError(t, err)
+
+	options := MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+	}
+
+	err = MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+
+	// The migration file is not needed to revert
+	err = os.Remove(secondFile)
+	require.NoError(t, err)
+
+	err = RevertArangoDatabaseMigration(ctx, db, options, "2")
+	require.NoError(t, err)
+
+	usersColl, err := db.GetCollection(ctx, "users", nil)
+	require.NoError(t, err)
+
+	exists, err := usersColl.DocumentExists(ctx, "admin")
+	require.NoError(t, err)
+	assert.False(t, exists, "Document should have been removed")
+
+	indexes, err := usersColl.Indexes(ctx)
+	require.NoError(t, err)
+	for _, index := range indexes {
+		assert.NotEqual(t, "idx_users_role", index.Name, "Index should have been removed")
+	}
+
+	migrationsColl, err := db.GetCollection(ctx, "migrations", nil)
+	require.NoError(t, err)
+
+	exists, err = migrationsColl.DocumentExists(ctx, "000002_seed_users")
+	require.NoError(t, err)
+	assert.False(t, exists, "Migration 000002_seed_users should no longer be recorded")
+
+	exists, err = migrationsColl.DocumentExists(ctx, "000001_users")
+	require.NoError(t, err)
+	assert.True(t, exists, "Migration 000001_users should still be recorded")
+
+	// Reverting a migration that is not applied fails
+	err = RevertArangoDatabaseMigration(ctx, db, options, "2")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has not been applied")
+}
@@ -0,0 +1,502 @@
+package migrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/arangodb/go-driver/v2/arangodb/shared"
+)
+
+// analyzerDefinition converts the options of a createAnalyzer operation to the
+// driver's analyzer definition.
+//
+//	{
+//		"type": "createAnalyzer",
+//		"name": "text_en_no_stem",
+//		"options": {
+//		  "type": "text",
+//		  "properties": {"locale": "en", "stemming": false},
+//		  "features": ["frequency", "norm", "position"]
+//		}
+//	}
+func analyzerDefinition(name string, options map[string]interface{}) (*arangodb.AnalyzerDefinition, error) {
+	if _, ok := options["type"].(string); !ok {
+		return nil, fmt.Errorf("analyzer type missing or not a string")
+	}
+
+	definition := &arangodb.AnalyzerDefinition{}
+	err := decodeOptions(options, definition)
+	if err != nil {
+		return nil, err
+	}
+
+	definition.Name = name
+	return definition, nil
+}
+
+func createAnalyzerWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:    "createAnalyzer",
+		Name:    name,
+		Options: options,
+		Result:  make(map[string]interface{}),
+	}
+
+	definition, err := analyzerDefinition(name, options)
+	if err != nil {
+		return result, err
+	}
+
+	// EnsureAnalyzer succeeds for an identical existing analyzer, which rollback would then delete
+	_, err = db.Analyzer(ctx, name)
+	if err == nil {
+		return result, fmt.Errorf("analyzer '%s' already exists", name)
+	}
+	if !shared.IsNotFound(err) {
+		return result, fmt.Errorf("failed to check if analyzer '%s' exists: %w", name, err)
+	}
+
+	_, _, err = db.EnsureAnalyzer(ctx, definition)
+	if err != nil {
+		return result, fmt.Errorf("failed to create analyzer: %w", err)
+	}
+
+	result.Result["analyzerName"] = name
+	return result, nil
+}
+
+//	{
+//		"type": "deleteAnalyzer",
+//		"name": "text_en_no_stem",
+//		"options": {
+//		  "force": true
+//		}
+//	}
+func deleteAnalyzerWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:         "deleteAnalyzer",
+		Name:         name,
+		Options:      options,
+		Result:       make(map[string]interface{}),
+		RollbackData: make(map[string]interface{}),
+	}
+
+	analyzer, err := db.Analyzer(ctx, name)
+	if err != nil {
+		return result, fmt.Errorf("failed to get analyzer '%s' for deletion: %w", name, err)
+	}
+
+	// Store the definition to recreate the analyzer on rollback
+	result.RollbackData["originalDefinition"] = analyzer.Definition()
+
+	force, _ := options["force"].(bool)
+	err = analyzer.Remove(ctx, force)
+	if err != nil {
+		return result, fmt.Errorf("failed to remove analyzer: %w", err)
+	}
+
+	return result, nil
+}
+
+func deleteAnalyzer(ctx context.Context, db arangodb.Database, name string) error {
+	analyzer, err := db.Analyzer(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to get analyzer '%s' for deletion: %w", name, err)
+	}
+
+	err = analyzer.Remove(ctx, false)
+	if err != nil {
+		return fmt.Errorf("failed to remove analyzer: %w", err)
+	}
+
+	return nil
+}
+
+// restoreAnalyzer recreates an analyzer from the definition recorded when it was deleted.
+func restoreAnalyzer(ctx context.Context, db arangodb.Database, operation OperationResult) error {
+	original, ok := operation.RollbackData["originalDefinition"]
+	if !ok {
+		return fmt.Errorf("cannot rollback analyzer deletion - no original definition available")
+	}
+
+	var definition arangodb.AnalyzerDefinition
+	err := remarshal(original, &definition)
+	if err != nil {
+		return fmt.Errorf("failed to decode original analyzer definition: %w", err)
+	}
+	definition.Name = operation.Name
+
+	_, _, err = db.EnsureAnalyzer(ctx, &definition)
+	if err != nil {
+		return fmt.Errorf("failed to restore analyzer: %w", err)
+	}
+
+	return nil
+}
+
+//	{
+//		"type": "createArangoSearchView",
+//		"name": "posts_view",
+//		"options": {
+//		  "links": {
+//		    "posts": {"analyzers": ["text_en"], "fields": {"title": {}, "body": {}}}
+//		  },
+//		  "primarySort": [{"field": "createdAt", "direction": "desc"}]
+//		}
+//	}
+func createArangoSearchView(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
+	var props arangodb.ArangoSearchViewProperties
+	err := decodeOptions(options, &props)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.CreateArangoSearchView(ctx, name, &props)
+	if err != nil {
+		return fmt.Errorf("failed to create arangosearch view: %w", err)
+	}
+
+	return nil
+}
+
+//	{
+//		"type": "createSearchAliasView",
+//		"name": "posts_search",
+//		"options": {
+//		  "indexes": [{"collection": "posts", "index": "idx_posts_search"}]
+//		}
+//	}
+func createSearchAliasView(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error {
+	var props arangodb.ArangoSearchAliasViewProperties
+	err := decodeOptions(options, &props)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.CreateArangoSearchAliasView(ctx, name, &props)
+	if err != nil {
+		return fmt.Errorf("failed to create search-alias view: %w", err)
+	}
+
+	return nil
+}
+
+func createViewWithTracking(ctx context.Context, db arangodb.Database, operationType string, name string, options map[string]interface{},
+	create func(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) error) (OperationResult, error) {
+	result := OperationResult{
+		Type:    operationType,
+		Name:    name,
+		Options: options,
+		Result:  make(map[string]interface{}),
+	}
+
+	err := create(ctx, db, name, options)
+	if err != nil {
+		return result, err
+	}
+
+	result.Result["viewName"] = name
+	return result, nil
+}
+
+// viewProperties returns the type and the current properties of a view.
+func viewProperties(ctx context.Context, db arangodb.Database, name string) (arangodb.ViewType, interface{}, error) {
+	view, err := db.View(ctx, name)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get view '%s': %w", name, err)
+	}
+
+	switch view.Type() {
+	case arangodb.ViewTypeArangoSearch:
+		searchView, err := view.ArangoSearchView()
+		if err != nil {
+			return "", nil, err
+		}
+		props, err := searchView.Properties(ctx)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read properties of view '%s': %w", name, err)
+		}
+		return view.Type(), props, nil
+
+	case arangodb.ViewTypeSearchAlias:
+		aliasView, err := view.ArangoSearchViewAlias()
+		if err != nil {
+			return "", nil, err
+		}
+		props, err := aliasView.Properties(ctx)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read properties of view '%s': %w", name, err)
+		}
+		return view.Type(), props, nil
+
+	default:
+		return "", nil, fmt.Errorf("unsupported view type: %s", view.Type())
+	}
+}
+
+// setViewProperties replaces the properties of a view. The properties are converted
+// to the driver's properties type for the view type.
+func setViewProperties(ctx context.Context, db arangodb.Database, name string, properties interface{}) error {
+	view, err := db.View(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to get view '%s': %w", name, err)
+	}
+
+	switch view.Type() {
+	case arangodb.ViewTypeArangoSearch:
+		var props arangodb.ArangoSearchViewProperties
+		err := remarshal(properties, &props)
+		if err != nil {
+			return err
+		}
+		searchView, err := view.ArangoSearchView()
+		if err != nil {
+			return err
+		}
+		err = searchView.SetProperties(ctx, props)
+		if err != nil {
+			return fmt.Errorf("failed to set properties of view '%s': %w", name, err)
+		}
+
+	case arangodb.ViewTypeSearchAlias:
+		var props arangodb.ArangoSearchAliasViewProperties
+		err := remarshal(properties, &props)
+		if err != nil {
+			return err
+		}
+		aliasView, err := view.ArangoSearchViewAlias()
+		if err != nil {
+			return err
+		}
+		err = aliasView.SetProperties(ctx, props)
+		if err != nil {
+			return fmt.Errorf("failed to set properties of view '%s': %w", name, err)
+		}
+
+	default:
+		return fmt.Errorf("unsupported view type: %s", view.Type())
+	}
+
+	return nil
+}
+
+// updateViewPropertiesWithTracking changes the given properties of a view and keeps
+// the others. Links of an arangosearch view are merged by collection, and a link set
+// to null is removed; other properties, such as the indexes of a search-alias view,
+// are replaced.
+//
+//	{
+//		"type": "updateViewProperties",
+//		"name": "posts_view",
+//		"options": {
+//		  "links": {
+//		    "comments": {"analyzers": ["text_en"], "fields": {"text": {}}}
+//		  }
+//		}
+//	}
+func updateViewPropertiesWithTracking(ctx context.Context, db arangodb.Database, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:         "updateViewProperties",
+		Name:         name,
+		Options:      options,
+		Result:       make(map[string]interface{}),
+		RollbackData: make(map[string]interface{}),
+	}
+
+	viewType, current, err := viewProperties(ctx, db, name)
+	if err != nil {
+		return result, err
+	}
+
+	// Unknown properties would be dropped when converting to the driver's properties
+	err = validateViewProperties(viewType, options)
+	if err != nil {
+		return result, err
+	}
+
+	// Store the current properties for rollback
+	result.RollbackData["originalProperties"] = current
+
+	var merged map[string]interface{}
+	err = remarshal(current, &merged)
+	if err != nil {
+		return result, err
+	}
+	if merged == nil {
+		merged = make(map[string]interface{})
+	}
+
+	for key, value := range options {
+		links, isLinks := value.(map[string]interface{})
+		currentLinks, hasLinks := merged[key].(map[string]interface{})
+		if key != "links" || !isLinks || !hasLinks {
+			merged[key] = value
+			continue
+		}
+		for collection, link := range links {
+			if link == nil {
+				delete(currentLinks, collection)
+			} else {
+				currentLinks[collection] = link
+			}
+		}
+	}
+
+	err = setViewProperties(ctx, db, name, merged)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// restoreViewProperties sets the properties recorded before an updateViewProperties
+// operation again.
+func restoreViewProperties(ctx context.Context, db arangodb.Database, operation OperationResult) error {
+	original, ok := operation.RollbackData["originalProperties"]
+	if !ok {
+		return fmt.Errorf("cannot rollback view update - no original properties available")
+	}
+
+	return setViewProperties(ctx, db, operation.Name, original)
+}
+
+//	{
+//		"type": "deleteView",
+//		"name": "posts_view"
+//	}
+func deleteViewWithTracking(ctx context.Context, db arangodb.Database, name string) (OperationResult, error) {
+	result := OperationResult{
+		Type:         "deleteView",
+		Name:         name,
+		Options:      make(map[string]interface{}),
+		Result:       make(map[string]interface{}),
+		RollbackData: make(map[string]interface{}),
+	}
+
+	viewType, props, err := viewProperties(ctx, db, name)
+	if err != nil {
+		return result, err
+	}
+
+	// Store the view definition to recreate the view on rollback
+	result.RollbackData["viewType"] = string(viewType)
+	result.RollbackData["originalProperties"] = props
+
+	err = deleteView(ctx, db, name)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func deleteView(ctx context.Context, db arangodb.Database, name string) error {
+	view, err := db.View(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to get view '%s' for deletion: %w", name, err)
+	}
+
+	err = view.Remove(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to remove view: %w", err)
+	}
+
+	return nil
+}
+
+// restoreView recreates a view from the definition recorded when it was deleted.
+func restoreView(ctx context.Context, db arangodb.Database, operation OperationResult) error {
+	viewType, _ := operation.RollbackData["viewType"].(string)
+	original, ok := operation.RollbackData["originalProperties"]
+	if !ok {
+		return fmt.Errorf("cannot rollback view deletion - no original properties available")
+	}
+
+	var options map[string]interface{}
+	err := remarshal(original, &options)
+	if err != nil {
+		return err
+	}
+
+	switch arangodb.ViewType(viewType) {
+	case arangodb.ViewTypeArangoSearch:
+		return createArangoSearchView(ctx, db, operation.Name, options)
+	case arangodb.ViewTypeSearchAlias:
+		return createSearchAliasView(ctx, db, operation.Name, options)
+	default:
+		return fmt.Errorf("cannot rollback deletion of view with unsupported type: %s", viewType)
+	}
+}
+
+// validateViewOptions checks the options of an operation that creates a view.
+func validateViewOptions(operationType string, options map[string]interface{}) error {
+	switch operationType {
+	case "createArangoSearchView":
+		return decodeOptions(options, &arangodb.ArangoSearchViewProperties{})
+	case "createSearchAliasView":
+		return decodeOptions(options, &arangodb.ArangoSearchAliasViewProperties{})
+	}
+	return nil
+}
+
+// validateViewProperties checks the options of an updateViewProperties operation
+// against the properties of the view type, or of any view type if it isn't known yet.
+func validateViewProperties(viewType arangodb.ViewType, options map[string]interface{}) error {
+	switch viewType {
+	case arangodb.ViewTypeArangoSearch:
+		return decodeOptions(options, &arangodb.ArangoSearchViewProperties{})
+	case arangodb.ViewTypeSearchAlias:
+		return decodeOptions(options, &arangodb.ArangoSearchAliasViewProperties{})
+	}
+
+	err := validateViewProperties(arangodb.ViewTypeArangoSearch, options)
+	if err != nil && validateViewProperties(arangodb.ViewTypeSearchAlias, options) == nil {
+		return nil
+	}
+	return err
+}
+
+// viewCollections returns the collections a view definition refers to, from the links
+// of an arangosearch view or the indexes of a search-alias view.
+func viewCollections(options map[string]interface{}) []string {
+	var collections []string
+	if links, ok := options["links"].(map[string]interface{}); ok {
+		for collection, link := range links {
+			if link != nil {
+				collections = append(collections, collection)
+			}
+		}
+	}
+	if indexes, ok := options["indexes"].([]interface{}); ok {
+		for _, index := range indexes {
+			if index, ok := index.(map[string]interface{}); ok {
+				if collection, ok := index["collection"].(string); ok {
+					collections = append(collections, collection)
+				}
+			}
+		}
+	}
+	sort.Strings(collections)
+	return collections
+}
+
+// remarshal converts a value to another type through JSON, such as driver structs
+// recorded in RollbackData that come back as maps when read from the migration
+// collection.
+func remarshal(value interface{}, target interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode value: %w", err)
+	}
+
+	err = json.Unmarshal(data, target)
+	if err != nil {
+		return fmt.Errorf("failed to decode value: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// MigrationState describes whether a migration has been applied to a database.
+type MigrationState string
+
+const (
+	// MigrationStateApplied means the migration is recorded as applied and its file is unchanged.
+	MigrationStateApplied MigrationState = "applied"
+
+	// MigrationStatePending means the migration file exists but has not been applied yet.
+	MigrationStatePending MigrationState = "pending"
+
+	// MigrationStateModified means the migration is applied but its file has changed since.
+	MigrationStateModified MigrationState = "modified"
+
+	// MigrationStateMissingFile means the migration is applied but its file no longer exists.
+	MigrationStateMissingFile MigrationState = "missing-file"
+
+	// MigrationStateError means the migration file can't be read or parsed. Error
+	// describes why.
+	MigrationStateError MigrationState = "error"
+)
+
+// MigrationStatus describes the state of a single migration.
+type MigrationStatus struct {
+	// MigrationNumber is the migration file name without extension (e.g., "000001").
+	MigrationNumber string `json:"migrationNumber"`
+
+	// Description is the description from the migration file, if the file exists.
+	Description string `json:"description,omitempty"`
+
+	// State is the current state of the migration.
+	State MigrationState `json:"state"`
+
+	// AppliedAt is the timestamp when the migration was applied, if it has been applied.
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+
+	// AppliedSha256 is the hash recorded when the migration was applied.
+	AppliedSha256 string `json:"appliedSha256,omitempty"`
+
+	// FileSha256 is the hash of the migration file as it currently exists. It is
+	// empty for Go migrations registered with Register.
+	FileSha256 string `json:"fileSha256,omitempty"`
+
+	// Error is why the migration file can't be read, if State is MigrationStateError.
+	Error string `json:"error,omitempty"`
+}
+
+// Status reports the state of every migration known to the migration folder or the
+// migration collection, ordered by numeric prefix. It does not modify the database:
+// if the migration collection doesn't exist yet, every migration file is reported as
+// pending. A migration file that can't be read is reported with MigrationStateError
+// instead of failing the whole status.
+//
+// # Examples
+//
+//	statuses, err := migrator.Status(ctx, db, migrator.MigrationOptions{
+//		MigrationFolder:     "./migrations",
+//		MigrationCollection: "migrations",
+//	})
+//	for _, status := range statuses {
+//		fmt.Printf("%s\t%s\n", status.MigrationNumber, status.State)
+//	}
+func Status(ctx context.Context, db arangodb.Database, options MigrationOptions) ([]MigrationStatus, error) {
+	return New(db, WithMigrationOptions(options)).Status(ctx)
+}
+
+// Status reports the state of every migration like the Status function.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	db, options := m.db, m.options
+	appliedByNumber := make(map[string]AppliedMigration)
+
+	exists, err := db.CollectionExists(ctx, options.MigrationCollection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if migration collection exists: %w", err)
+	}
+
+	if exists {
+		appliedMigrations, err := readAppliedMigrations(ctx, db, options.MigrationCollection)
+		if err != nil {
+			return nil, err
+		}
+		for _, appliedMigration := range appliedMigrations {
+			appliedByNumber[appliedMigration.MigrationNumber] = appliedMigration
+		}
+	}
+
+	migrationFiles, err := listMigrations(options)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, migrationFile := range migrationFiles {
+		status := MigrationStatus{
+			MigrationNumber: migrationFile.MigrationNumber,
+			State:           MigrationStatePending,
+		}
+
+		if migrationFile.GoMigration != nil {
+			status.Description = migrationFile.GoMigration.migration().Description
+		} else {
+			status.FileSha256, err = getMigrationSHA256(migrationFile.FS, migrationFile.Path)
+			if err == nil {
+				var migration *Migration
+				migration, err = readMigrationFile(migrationFile.FS, migrationFile.Path)
+				if err == nil {
+					status.Description = migration.Description
+				}
+			}
+			if err != nil {
+				status.State = MigrationStateError
+				status.Error = fmt.Sprintf("failed to read migration file: %v", err)
+			}
+		}
+
+		if appliedMigration, ok := appliedByNumber[migrationFile.MigrationNumber]; ok {
+			appliedAt := appliedMigration.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.AppliedSha256 = appliedMigration.Sha256
+			if status.State != MigrationStateError {
+				status.State = MigrationStateApplied
+				if appliedMigration.Sha256 != status.FileSha256 {
+					status.State = MigrationStateModified
+				}
+			}
+			delete(appliedByNumber, migrationFile.MigrationNumber)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	// Anything left was applied from a file that no longer exists
+	for _, appliedMigration := range appliedByNumber {
+		appliedAt := appliedMigration.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			MigrationNumber: appliedMigration.MigrationNumber,
+			State:           MigrationStateMissingFile,
+			AppliedAt:       &appliedAt,
+			AppliedSha256:   appliedMigration.Sha256,
+		})
+	}
+
+	sortMigrationStatuses(statuses)
+	return statuses, nil
+}
+
+// sortMigrationStatuses orders statuses by numeric prefix.
+func sortMigrationStatuses(statuses []MigrationStatus) {
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return migrationNumberLess(statuses[i].MigrationNumber, statuses[j].MigrationNumber)
+	})
+}
+
+// migrationNumberLess orders migration numbers by numeric prefix, falling back to
+// the migration number for entries without one.
+func migrationNumberLess(a string, b string) bool {
+	va, errA := migrationVersion(a)
+	vb, errB := migrationVersion(b)
+	if errA == nil && errB == nil && va != vb {
+		return va < vb
+	}
+	return a < b
+}
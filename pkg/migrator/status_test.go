@@ -0,0 +1,126 @@
+package migrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FramnkRulez/go-arangodb-migrator/pkg/migrator/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrationStatusOrdering tests that statuses are ordered by numeric prefix
+func TestMigrationStatusOrdering(t *testing.T) {
+	statuses := []MigrationStatus{
+		{MigrationNumber: "10_later"},
+		{MigrationNumber: "000002_second"},
+		{MigrationNumber: "1_first"},
+	}
+
+	sortMigrationStatuses(statuses)
+
+	assert.Equal(t, "1_first", statuses[0].MigrationNumber)
+	assert.Equal(t, "000002_second", statuses[1].MigrationNumber)
+	assert.Equal(t, "10_later", statuses[2].MigrationNumber)
+}
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+
+	// Start ArangoDB container
+	container := testutil.NewArangoDBContainer(ctx, t)
+	defer container.Cleanup(ctx)
+
+	// Create test database
+	db := container.CreateTestDatabase(ctx, t, "test_status")
+
+	tempDir := t.TempDir()
+	migration := func(collection string) string {
+		return `{
+			"description": "Create ` + collection + `",
+			"up": [
+				{
+					"type": "createCollection",
+					"name": "` + collection + `",
+					"options": {
+						"type": "document"
+					}
+				}
+			]
+		}`
+	}
+
+	options := MigrationOptions{
+		MigrationFolder:     tempDir,
+		MigrationCollection: "migrations",
+	}
+
+	for number, collection := range map[string]string{
+		"000001_first":  "first",
+		"000002_second": "second",
+		"000003_third":  "third",
+	} {
+		err := os.WriteFile(filepath.Join(tempDir, number+".json"), []byte(migration(collection)), 0644)
+		require.NoError(t, err)
+	}
+
+	t.Run("before any migration", func(t *testing.T) {
+		statuses, err := Status(ctx, db, options)
+		require.NoError(t, err)
+		require.Len(t, statuses, 3)
+
+		for _, status := range statuses {
+			assert.Equal(t, MigrationStatePending, status.State)
+			assert.Nil(t, status.AppliedAt)
+		}
+
+		// Status must not create the migration collection
+		exists, err := db.CollectionExists(ctx, "migrations")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	err := MigrateArangoDatabase(ctx, db, options)
+	require.NoError(t, err)
+
+	// Add a pending migration, modify an applied one and remove another
+	err = os.WriteFile(filepath.Join(tempDir, "000004_fourth.json"), []byte(migration("fourth")), 0644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "000002_second.json"), []byte(migration("second_modified")), 0644)
+	require.NoError(t, err)
+	err = os.Remove(filepath.Join(tempDir, "000003_third.json"))
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "000005_broken.json"), []byte(`{"up": [`), 0644)
+	require.NoError(t, err)
+
+	t.Run("mixed states", func(t *testing.T) {
+		statuses, err := Status(ctx, db, options)
+		require.NoError(t, err)
+		require.Len(t, statuses, 5)
+
+		assert.Equal(t, "000001_first", statuses[0].MigrationNumber)
+		assert.Equal(t, MigrationStateApplied, statuses[0].State)
+		assert.Equal(t, "Create first", statuses[0].Description)
+		assert.NotNil(t, statuses[0].AppliedAt)
+		assert.Equal(t, statuses[0].AppliedSha256, statuses[0].FileSha256)
+
+		assert.Equal(t, "000002_second", statuses[1].MigrationNumber)
+		assert.Equal(t, MigrationStateModified, statuses[1].State)
+		assert.NotEqual(t, statuses[1].AppliedSha256, statuses[1].FileSha256)
+
+		assert.Equal(t, "000003_third", statuses[2].MigrationNumber)
+		assert.Equal(t, MigrationStateMissingFile, statuses[2].State)
+		assert.NotNil(t, statuses[2].AppliedAt)
+
+		assert.Equal(t, "000004_fourth", statuses[3].MigrationNumber)
+		assert.Equal(t, MigrationStatePending, statuses[3].State)
+		assert.Nil(t, statuses[3].AppliedAt)
+
+		// A broken file doesn't hide the other migrations
+		assert.Equal(t, "000005_broken", statuses[4].MigrationNumber)
+		assert.Equal(t, MigrationStateError, statuses[4].State)
+		assert.Contains(t, statuses[4].Error, "failed to read migration file")
+	})
+}
@@ -0,0 +1,117 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// transactionalOperationTypes are the operation types a transactional migration may
+// contain. Stream transactions only cover document changes, so collections, indexes
+// and graphs can't be created or deleted in them.
+var transactionalOperationTypes = map[string]bool{
+	"addDocument":    true,
+	"updateDocument": true,
+	"deleteDocument": true,
+}
+
+// validateTransactionalOperation checks that an operation can run in a transactional
+// migration.
+func validateTransactionalOperation(operation Operation) error {
+	if !transactionalOperationTypes[operation.Type] {
+		return fmt.Errorf("operation type %s can't be used in a transactional migration", operation.Type)
+	}
+	return nil
+}
+
+// transactionCollections returns the collections written by the operations of a
+// transactional migration, which the stream transaction has to declare up front.
+func transactionCollections(operations []Operation) ([]string, error) {
+	seen := make(map[string]bool)
+	var collections []string
+
+	for _, operation := range operations {
+		err := validateTransactionalOperation(operation)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[operation.Name] {
+			seen[operation.Name] = true
+			collections = append(collections, operation.Name)
+		}
+	}
+
+	sort.Strings(collections)
+	return collections, nil
+}
+
+// applyTransactionalOperations applies the operations of a transactional migration in
+// a single stream transaction. Either all operations are committed or, if one of them
+// fails, the transaction is aborted and none of them are.
+func (m *Migrator) applyTransactionalOperations(ctx context.Context, migrationNumber string, direction Direction, operations []Operation) ([]OperationResult, error) {
+	db := m.db
+
+	collections, err := transactionCollections(operations)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTransaction(ctx, arangodb.TransactionCollections{Write: collections}, &arangodb.BeginTransactionOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	m.options.logger().Debug("began transaction", "migration", migrationNumber, "transaction", tx.ID(), "collections", collections)
+
+	var operationResults []OperationResult
+	for i, operation := range operations {
+		event := OperationEvent{MigrationNumber: migrationNumber, Direction: direction, Index: i, Operation: operation}
+		operationResult, err := m.runOperation(ctx, event, func(ctx context.Context, operation Operation) (OperationResult, error) {
+			return applyTransactionalOperation(ctx, tx, operation)
+		})
+		if err != nil {
+			abortErr := tx.Abort(ctx, &arangodb.AbortTransactionOptions{})
+			if abortErr != nil {
+				return nil, operationError(migrationNumber, direction, i, operation, fmt.Errorf("operation %s on %s failed: %w; failed to abort transaction: %w", operation.Type, operation.Name, err, abortErr))
+			}
+			return nil, operationError(migrationNumber, direction, i, operation, fmt.Errorf("operation %s on %s failed, transaction aborted: %w", operation.Type, operation.Name, err))
+		}
+		operationResults = append(operationResults, operationResult)
+	}
+
+	err = tx.Commit(ctx, &arangodb.CommitTransactionOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return operationResults, nil
+}
+
+// applyTransactionalOperation dispatches a document operation to its tracking
+// implementation, with collections read through the transaction.
+func applyTransactionalOperation(ctx context.Context, tx arangodb.Transaction, operation Operation) (OperationResult, error) {
+	var operationResult OperationResult
+	var err error
+
+	switch operation.Type {
+	case "addDocument":
+		operationResult, err = addDocumentWithTracking(ctx, tx, operation.Name, operation.Options)
+	case "updateDocument":
+		operationResult, err = updateDocumentWithTracking(ctx, tx, operation.Name, operation.Options)
+	case "deleteDocument":
+		operationResult, err = deleteDocumentWithTracking(ctx, tx, operation.Name, operation.Options)
+	default:
+		err = validateTransactionalOperation(operation)
+	}
+
+	if err != nil {
+		return operationResult, err
+	}
+
+	operationResult.Type = operation.Type
+	operationResult.Name = operation.Name
+	operationResult.Options = operation.Options
+	return operationResult, nil
+}
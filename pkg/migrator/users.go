@@ -0,0 +1,368 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// grants are the access levels accepted by grantDatabaseAccess and
+// grantCollectionAccess.
+var grants = map[string]arangodb.Grant{
+	"rw":   arangodb.GrantReadWrite,
+	"ro":   arangodb.GrantReadOnly,
+	"none": arangodb.GrantNone,
+}
+
+// passwordOptions are the options that may hold a password and are left out of the
+// options recorded with an applied migration.
+var passwordOptions = []string{"password", "passwd"}
+
+// requireClient returns an error if the client needed by user operations is missing.
+func requireClient(client arangodb.Client, operationType string) error {
+	if client == nil {
+		return fmt.Errorf("%s requires MigrationOptions.Client", operationType)
+	}
+	return nil
+}
+
+// userOptions converts the options of a createUser operation to the driver's user
+// options. The password is given directly or read from an environment variable, which
+// keeps it out of migration files. It is never stored in the migration collection.
+//
+//	{
+//		"type": "createUser",
+//		"name": "orders-service",
+//		"options": {
+//		  "passwordEnv": "ORDERS_SERVICE_PASSWORD",
+//		  "active": true,
+//		  "extra": {"team": "orders"}
+//		}
+//	}
+func userOptions(options map[string]interface{}) (*arangodb.UserOptions, error) {
+	userOptions := &arangodb.UserOptions{}
+
+	_, hasPassword := options["password"]
+	_, hasPasswordEnv := options["passwordEnv"]
+	if hasPassword && hasPasswordEnv {
+		return nil, fmt.Errorf("only one of the password and passwordEnv options can be set")
+	}
+
+	if hasPassword {
+		password, ok := options["password"].(string)
+		if !ok {
+			return nil, fmt.Errorf("password option not a string")
+		}
+		userOptions.Password = password
+	}
+
+	if hasPasswordEnv {
+		variable, ok := options["passwordEnv"].(string)
+		if !ok || variable == "" {
+			return nil, fmt.Errorf("passwordEnv option not a string")
+		}
+		userOptions.Password = os.Getenv(variable)
+	}
+
+	if value, exists := options["active"]; exists {
+		active, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("active option not a boolean")
+		}
+		userOptions.Active = &active
+	}
+
+	if value, exists := options["extra"]; exists {
+		if _, ok := value.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("extra option not an object")
+		}
+		userOptions.Extra = value
+	}
+
+	return userOptions, nil
+}
+
+func createUserWithTracking(ctx context.Context, client arangodb.Client, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:    "createUser",
+		Name:    name,
+		Options: omitOptions(options, passwordOptions...),
+		Result:  make(map[string]interface{}),
+	}
+
+	err := requireClient(client, "createUser")
+	if err != nil {
+		return result, err
+	}
+
+	userOptions, err := userOptions(options)
+	if err != nil {
+		return result, err
+	}
+
+	if variable, ok := options["passwordEnv"].(string); ok && userOptions.Password == "" {
+		return result, fmt.Errorf("environment variable %s for the password of user '%s' is not set", variable, name)
+	}
+
+	_, err = client.CreateUser(ctx, name, userOptions)
+	if err != nil {
+		return result, fmt.Errorf("failed to create user '%s': %w", name, err)
+	}
+
+	result.Result["userName"] = name
+	return result, nil
+}
+
+func removeUser(ctx context.Context, client arangodb.Client, name string) error {
+	err := requireClient(client, "removing a user")
+	if err != nil {
+		return err
+	}
+
+	err = client.RemoveUser(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to remove user '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// databaseOptions converts the options of a createDatabase operation to the driver's
+// database options. The options are the defaults of collections created in the
+// database; users are created with createUser and granted access to it.
+//
+//	{
+//		"type": "createDatabase",
+//		"name": "analytics",
+//		"options": {
+//		  "replicationFactor": 3,
+//		  "writeConcern": 2,
+//		  "sharding": "single"
+//		}
+//	}
+func databaseOptions(options map[string]interface{}) (*arangodb.CreateDatabaseOptions, error) {
+	databaseOptions := &arangodb.CreateDatabaseOptions{}
+	err := decodeOptions(options, &databaseOptions.Options)
+	if err != nil {
+		return nil, err
+	}
+	return databaseOptions, nil
+}
+
+func createDatabaseWithTracking(ctx context.Context, client arangodb.Client, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:    "createDatabase",
+		Name:    name,
+		Options: options,
+		Result:  make(map[string]interface{}),
+	}
+
+	err := requireClient(client, "createDatabase")
+	if err != nil {
+		return result, err
+	}
+
+	databaseOptions, err := databaseOptions(options)
+	if err != nil {
+		return result, err
+	}
+
+	_, err = client.CreateDatabase(ctx, name, databaseOptions)
+	if err != nil {
+		return result, fmt.Errorf("failed to create database '%s': %w", name, err)
+	}
+
+	result.Result["databaseName"] = name
+	return result, nil
+}
+
+func deleteDatabase(ctx context.Context, client arangodb.Client, name string) error {
+	err := requireClient(client, "removing a database")
+	if err != nil {
+		return err
+	}
+
+	db, err := client.GetDatabase(ctx, name, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get database '%s': %w", name, err)
+	}
+
+	err = db.Remove(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to remove database '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// accessTarget returns the database and the optional collection an access operation
+// applies to. The database defaults to the migrated database.
+func accessTarget(db arangodb.Database, options map[string]interface{}) (string, string) {
+	database, _ := options["database"].(string)
+	if database == "" {
+		database = db.Name()
+	}
+	collection, _ := options["collection"].(string)
+	return database, collection
+}
+
+// getAccess returns the access level of a user to a database, or to a collection if
+// collection is not empty.
+func getAccess(ctx context.Context, user arangodb.User, database string, collection string) (arangodb.Grant, error) {
+	if collection != "" {
+		return user.GetCollectionAccess(ctx, database, collection)
+	}
+	return user.GetDatabaseAccess(ctx, database)
+}
+
+// setAccess sets the access level of a user to a database, or to a collection if
+// collection is not empty. Setting no access level removes the explicit access level,
+// so the user falls back to the default.
+func setAccess(ctx context.Context, user arangodb.User, database string, collection string, grant arangodb.Grant) error {
+	switch {
+	case grant == "" && collection != "":
+		return user.RemoveCollectionAccess(ctx, database, collection)
+	case grant == "":
+		return user.RemoveDatabaseAccess(ctx, database)
+	case collection != "":
+		return user.SetCollectionAccess(ctx, database, collection, grant)
+	default:
+		return user.SetDatabaseAccess(ctx, database, grant)
+	}
+}
+
+// changeAccessWithTracking sets or, for an empty grant, revokes the access of a user
+// to a database or collection and records the previous access level for rollback.
+//
+//	{
+//		"type": "grantCollectionAccess",
+//		"name": "orders-service",
+//		"options": {
+//		  "collection": "orders",
+//		  "grant": "rw"
+//		}
+//	}
+//
+//	{
+//		"type": "revokeAccess",
+//		"name": "reporting",
+//		"options": {
+//		  "database": "analytics"
+//		}
+//	}
+func changeAccessWithTracking(ctx context.Context, db arangodb.Database, client arangodb.Client, operationType string, name string, options map[string]interface{}) (OperationResult, error) {
+	result := OperationResult{
+		Type:         operationType,
+		Name:         name,
+		Options:      omitOptions(options, passwordOptions...),
+		Result:       make(map[string]interface{}),
+		RollbackData: make(map[string]interface{}),
+	}
+
+	err := requireClient(client, operationType)
+	if err != nil {
+		return result, err
+	}
+
+	err = validateAccessOptions(operationType, options)
+	if err != nil {
+		return result, err
+	}
+
+	database, collection := accessTarget(db, options)
+
+	var grant arangodb.Grant
+	if operationType != "revokeAccess" {
+		grant = grants[options["grant"].(string)]
+	}
+
+	user, err := client.User(ctx, name)
+	if err != nil {
+		return result, fmt.Errorf("failed to get user '%s': %w", name, err)
+	}
+
+	previous, err := getAccess(ctx, user, database, collection)
+	if err != nil {
+		return result, fmt.Errorf("failed to read access of user '%s': %w", name, err)
+	}
+	result.RollbackData["database"] = database
+	result.RollbackData["collection"] = collection
+	result.RollbackData["previousGrant"] = string(previous)
+
+	err = setAccess(ctx, user, database, collection, grant)
+	if err != nil {
+		return result, fmt.Errorf("failed to change access of user '%s': %w", name, err)
+	}
+
+	return result, nil
+}
+
+// rollbackChangeAccess restores the access level recorded before an access operation.
+// An explicit "none" is restored as it was, while an undefined access level is
+// removed again.
+func rollbackChangeAccess(ctx context.Context, client arangodb.Client, operation OperationResult) error {
+	err := requireClient(client, "rolling back "+operation.Type)
+	if err != nil {
+		return err
+	}
+
+	database, ok := operation.RollbackData["database"].(string)
+	if !ok {
+		return fmt.Errorf("cannot rollback %s - no previous access available", operation.Type)
+	}
+	collection, _ := operation.RollbackData["collection"].(string)
+
+	previousGrant, _ := operation.RollbackData["previousGrant"].(string)
+	previous := arangodb.Grant(previousGrant)
+	if previous == arangodb.GrantUndefined {
+		previous = ""
+	}
+
+	user, err := client.User(ctx, operation.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get user '%s': %w", operation.Name, err)
+	}
+
+	err = setAccess(ctx, user, database, collection, previous)
+	if err != nil {
+		return fmt.Errorf("failed to restore access of user '%s': %w", operation.Name, err)
+	}
+
+	return nil
+}
+
+// validateAccessOptions checks the options of grantDatabaseAccess,
+// grantCollectionAccess and revokeAccess.
+func validateAccessOptions(operationType string, options map[string]interface{}) error {
+	for _, key := range []string{"database", "collection"} {
+		if value, exists := options[key]; exists {
+			if s, ok := value.(string); !ok || s == "" {
+				return fmt.Errorf("%s option not a string", key)
+			}
+		}
+	}
+
+	if operationType == "grantCollectionAccess" {
+		if _, ok := options["collection"].(string); !ok {
+			return fmt.Errorf("collection option missing or not a string")
+		}
+	}
+	if operationType == "grantDatabaseAccess" {
+		if _, exists := options["collection"]; exists {
+			return fmt.Errorf("collection option can't be used with grantDatabaseAccess, use grantCollectionAccess")
+		}
+	}
+
+	if operationType == "revokeAccess" {
+		return nil
+	}
+
+	grant, _ := options["grant"].(string)
+	if _, ok := grants[grant]; !ok {
+		return fmt.Errorf("grant option must be one of rw, ro or none")
+	}
+
+	return nil
+}
@@ -0,0 +1,519 @@
+package migrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// ValidationError describes a single problem found while validating a migration folder.
+type ValidationError struct {
+	// MigrationNumber is the migration file name without extension (e.g., "000001").
+	MigrationNumber string `json:"migrationNumber"`
+
+	// List is the operation list the problem was found in ("up" or "down"). It is
+	// empty for problems with the file itself.
+	List string `json:"list,omitempty"`
+
+	// OperationIndex is the position of the operation in its list.
+	OperationIndex int `json:"operationIndex"`
+
+	// OperationType is the type of the operation (e.g., "createPersistentIndex").
+	OperationType string `json:"operationType,omitempty"`
+
+	// Name is the name of the resource the operation works on.
+	Name string `json:"name,omitempty"`
+
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	if e.List == "" {
+		return fmt.Sprintf("migration %s: %s", e.MigrationNumber, e.Message)
+	}
+	return fmt.Sprintf("migration %s: %s[%d] %s '%s': %s", e.MigrationNumber, e.List, e.OperationIndex, e.OperationType, e.Name, e.Message)
+}
+
+// ValidationErrors is returned by ValidateFolder when one or more problems are found.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, validationError := range e {
+		messages[i] = validationError.Error()
+	}
+	return fmt.Sprintf("%d migration validation errors:\n%s", len(e), strings.Join(messages, "\n"))
+}
+
+// ValidateFolder statically checks every migration file in a folder without
+// connecting to a database. It reports:
+//   - files that cannot be parsed or have an empty 'up' list
+//   - operations with an unknown type
+//   - operations with missing or mistyped options (e.g., "unique": "true")
+//   - references to resources not created by an earlier 'up' operation in the
+//     folder, such as an index on a collection that doesn't exist yet, or an edge
+//     definition using a collection that was never created or already deleted
+//
+// References are resolved against the migration folder only, so a folder that
+// relies on collections created outside of migrations will report them.
+//
+// Returns nil if the folder is valid, ValidationErrors listing every problem found,
+// or another error if the folder cannot be read.
+//
+// # Examples
+//
+//	err := migrator.ValidateFolder("./migrations")
+//	var validationErrors migrator.ValidationErrors
+//	if errors.As(err, &validationErrors) {
+//		for _, validationError := range validationErrors {
+//			fmt.Println(validationError)
+//		}
+//	}
+func ValidateFolder(folder string) error {
+	return ValidateFS(os.DirFS(folder), ".")
+}
+
+// ValidateFS is like ValidateFolder, but reads the migration files in dir from fsys,
+// such as an embed.FS used as MigrationOptions.MigrationFS.
+//
+// # Examples
+//
+//	//go:embed migrations
+//	var migrations embed.FS
+//
+//	err := migrator.ValidateFS(migrations, "migrations")
+func ValidateFS(fsys fs.FS, dir string) error {
+	migrationFiles, err := listMigrationFiles(fsys, dir, defaultLogger)
+	if err != nil {
+		return fmt.Errorf("failed to read migration folder: %w", err)
+	}
+
+	var validationErrors ValidationErrors
+	state := newValidationState()
+
+	for _, migrationFile := range migrationFiles {
+		migration, err := readMigrationFile(fsys, migrationFile.Path)
+		if err != nil {
+			validationErrors = append(validationErrors, &ValidationError{
+				MigrationNumber: migrationFile.MigrationNumber,
+				Message:         fmt.Sprintf("failed to parse migration file: %v", err),
+			})
+			continue
+		}
+
+		if len(migration.Up) == 0 {
+			validationErrors = append(validationErrors, &ValidationError{
+				MigrationNumber: migrationFile.MigrationNumber,
+				Message:         "does not include a valid 'up' list of migrations to apply",
+			})
+		}
+
+		for i, operation := range migration.Up {
+			err := validateOperation(operation)
+			if err == nil && migration.Transactional {
+				err = validateTransactionalOperation(operation)
+			}
+			if err == nil {
+				err = validateImportFile(fsys, dir, operation)
+			}
+			if err == nil {
+				err = state.apply(operation)
+			}
+			if err != nil {
+				validationErrors = append(validationErrors, newOperationValidationError(migrationFile.MigrationNumber, "up", i, operation, err))
+			}
+		}
+
+		// Down operations undo this migration, so only their options are checked
+		for i, operation := range migration.Down {
+			err := validateOperation(operation)
+			if err == nil && migration.Transactional {
+				err = validateTransactionalOperation(operation)
+			}
+			if err == nil {
+				err = validateImportFile(fsys, dir, operation)
+			}
+			if err != nil {
+				validationErrors = append(validationErrors, newOperationValidationError(migrationFile.MigrationNumber, "down", i, operation, err))
+			}
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+func newOperationValidationError(migrationNumber string, list string, index int, operation Operation, err error) *ValidationError {
+	return &ValidationError{
+		MigrationNumber: migrationNumber,
+		List:            list,
+		OperationIndex:  index,
+		OperationType:   operation.Type,
+		Name:            operation.Name,
+		Message:         err.Error(),
+	}
+}
+
+// validateIndexOptions checks the options of an operation that creates an index by
+// converting them to the driver's index options.
+func validateIndexOptions(operationType string, name string, options map[string]interface{}) error {
+	if _, ok := options["collection"].(string); !ok {
+		return fmt.Errorf("collection name missing or not a string")
+	}
+
+	var err error
+	switch operationType {
+	case "createPersistentIndex":
+		_, _, err = persistentIndexOptions(name, options)
+	case "createGeoIndex":
+		_, _, err = geoIndexOptions(name, options)
+	case "createTTLIndex":
+		_, _, _, err = ttlIndexOptions(name, options)
+	case "createInvertedIndex":
+		_, err = invertedIndexOptions(name, options)
+	case "createMDIIndex":
+		_, _, err = mdiIndexOptions(name, options)
+	case "createVectorIndex":
+		_, err = vectorIndexOptions(name, options)
+	case "createFulltextIndex":
+		_, err = fulltextIndexOptions(name, options)
+	}
+	return err
+}
+
+// parseEdgeDefinitions parses the edgeDefinitions option of a createGraph operation.
+func parseEdgeDefinitions(options map[string]interface{}) ([]arangodb.EdgeDefinition, error) {
+	edges, ok := options["edgeDefinitions"]
+	if !ok {
+		return nil, fmt.Errorf("edgeDefinitions option missing or not an array")
+	}
+
+	// Round-trip through JSON so options built in Go and parsed from files look the same
+	bytes, err := json.Marshal(edges)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal edge definition options: %w", err)
+	}
+
+	var edgeOptionsList []map[string]interface{}
+	err = json.Unmarshal(bytes, &edgeOptionsList)
+	if err != nil {
+		return nil, fmt.Errorf("edgeDefinitions option not an array of objects")
+	}
+
+	edgeDefinitions := make([]arangodb.EdgeDefinition, 0, len(edgeOptionsList))
+	for i, edgeOptions := range edgeOptionsList {
+		edgeDefinition, err := parseEdgeDefinition(edgeOptions)
+		if err != nil {
+			return nil, fmt.Errorf("edgeDefinitions[%d]: %w", i, err)
+		}
+		edgeDefinitions = append(edgeDefinitions, edgeDefinition)
+	}
+
+	return edgeDefinitions, nil
+}
+
+// parseEdgeDefinition parses a single edge definition with a collection and
+// from/to vertex collections.
+func parseEdgeDefinition(options map[string]interface{}) (arangodb.EdgeDefinition, error) {
+	var edgeDefinition arangodb.EdgeDefinition
+
+	if _, ok := options["collection"].(string); !ok {
+		return edgeDefinition, fmt.Errorf("collection option missing or not a string")
+	}
+	for _, key := range []string{"from", "to"} {
+		vertices, ok := getSlice[string](options, key)
+		if !ok || len(vertices) == 0 {
+			return edgeDefinition, fmt.Errorf("%s option missing or not a non-empty string array", key)
+		}
+	}
+
+	bytes, err := json.Marshal(options)
+	if err != nil {
+		return edgeDefinition, fmt.Errorf("failed to marshal edge definition options: %w", err)
+	}
+
+	err = json.Unmarshal(bytes, &edgeDefinition)
+	if err != nil {
+		return edgeDefinition, fmt.Errorf("failed to unmarshal edge definition options: %w", err)
+	}
+
+	return edgeDefinition, nil
+}
+
+// validationState tracks the resources created by the 'up' operations validated so
+// far, so later operations can be checked against them.
+type validationState struct {
+	// collections maps collection names to their type ("document" or "edge")
+	collections map[string]string
+	indexes     map[string]bool
+	graphs      map[string]bool
+	views       map[string]bool
+	analyzers   map[string]bool
+	users       map[string]bool
+	databases   map[string]bool
+}
+
+func newValidationState() *validationState {
+	return &validationState{
+		collections: make(map[string]string),
+		indexes:     make(map[string]bool),
+		graphs:      make(map[string]bool),
+		views:       make(map[string]bool),
+		analyzers:   make(map[string]bool),
+		users:       make(map[string]bool),
+		databases:   make(map[string]bool),
+	}
+}
+
+// apply checks the references of an operation whose options are valid and records
+// the resources it creates or deletes.
+func (s *validationState) apply(operation Operation) error {
+	options := operation.Options
+
+	switch operation.Type {
+	case "createCollection":
+		if _, exists := s.collections[operation.Name]; exists {
+			return fmt.Errorf("collection '%s' is already created by an earlier operation", operation.Name)
+		}
+		s.collections[operation.Name] = options["type"].(string)
+
+	case "deleteCollection":
+		err := s.requireCollection(operation.Name)
+		if err != nil {
+			return err
+		}
+		delete(s.collections, operation.Name)
+		for key := range s.indexes {
+			if strings.HasPrefix(key, operation.Name+"/") {
+				delete(s.indexes, key)
+			}
+		}
+
+	case "renameCollection":
+		err := s.requireCollection(operation.Name)
+		if err != nil {
+			return err
+		}
+		newName := options["newName"].(string)
+		if _, exists := s.collections[newName]; exists {
+			return fmt.Errorf("collection '%s' is already created by an earlier operation", newName)
+		}
+		s.collections[newName] = s.collections[operation.Name]
+		delete(s.collections, operation.Name)
+		for key := range s.indexes {
+			if strings.HasPrefix(key, operation.Name+"/") {
+				delete(s.indexes, key)
+				s.indexes[newName+"/"+strings.TrimPrefix(key, operation.Name+"/")] = true
+			}
+		}
+
+	case "truncateCollection":
+		err := s.requireCollection(operation.Name)
+		if err != nil {
+			return err
+		}
+		_, backupCollection, _ := truncateBackup(operation.Name, options)
+		if backupCollection != "" {
+			if _, exists := s.collections[backupCollection]; exists {
+				return fmt.Errorf("collection '%s' is already created by an earlier operation", backupCollection)
+			}
+			s.collections[backupCollection] = s.collections[operation.Name]
+		}
+
+	case "createPersistentIndex", "createGeoIndex", "createTTLIndex", "createInvertedIndex", "createMDIIndex", "createVectorIndex", "createFulltextIndex":
+		collName := options["collection"].(string)
+		err := s.requireCollection(collName)
+		if err != nil {
+			return err
+		}
+		s.indexes[collName+"/"+operation.Name] = true
+
+	case "deleteIndex":
+		collName := options["collection"].(string)
+		err := s.requireCollection(collName)
+		if err != nil {
+			return err
+		}
+		if !s.indexes[collName+"/"+operation.Name] {
+			return fmt.Errorf("index '%s' on collection '%s' is not created by an earlier operation", operation.Name, collName)
+		}
+		delete(s.indexes, collName+"/"+operation.Name)
+
+	case "createGraph":
+		if s.graphs[operation.Name] {
+			return fmt.Errorf("graph '%s' is already created by an earlier operation", operation.Name)
+		}
+		edgeDefinitions, err := parseEdgeDefinitions(options)
+		if err != nil {
+			return err
+		}
+		for _, edgeDefinition := range edgeDefinitions {
+			err := s.requireEdgeDefinition(edgeDefinition)
+			if err != nil {
+				return err
+			}
+		}
+		orphanedCollections, err := getOrphanedCollections(options)
+		if err != nil {
+			return err
+		}
+		for _, orphanedCollection := range orphanedCollections {
+			err := s.requireCollection(orphanedCollection)
+			if err != nil {
+				return err
+			}
+		}
+		s.graphs[operation.Name] = true
+
+	case "addEdgeDefinition", "replaceEdgeDefinition":
+		err := s.requireGraph(operation.Name)
+		if err != nil {
+			return err
+		}
+		edgeDefinition, err := parseEdgeDefinition(edgeDefinitionOptions(options))
+		if err != nil {
+			return err
+		}
+		return s.requireEdgeDefinition(edgeDefinition)
+
+	case "deleteEdgeDefinition":
+		return s.requireGraph(operation.Name)
+
+	case "addVertexCollection":
+		err := s.requireGraph(operation.Name)
+		if err != nil {
+			return err
+		}
+		// A missing vertex collection is created when it is added to the graph
+		collection := options["collection"].(string)
+		if _, exists := s.collections[collection]; !exists {
+			s.collections[collection] = "document"
+		}
+
+	case "removeVertexCollection":
+		err := s.requireGraph(operation.Name)
+		if err != nil {
+			return err
+		}
+		return s.requireCollection(options["collection"].(string))
+
+	case "modifyCollection", "setCollectionSchema", "addDocument", "updateDocument", "deleteDocument", "importDocuments":
+		return s.requireCollection(operation.Name)
+
+	case "createDatabase":
+		if s.databases[operation.Name] {
+			return fmt.Errorf("database '%s' is already created by an earlier operation", operation.Name)
+		}
+		s.databases[operation.Name] = true
+
+	case "createUser":
+		if s.users[operation.Name] {
+			return fmt.Errorf("user '%s' is already created by an earlier operation", operation.Name)
+		}
+		s.users[operation.Name] = true
+
+	case "grantCollectionAccess":
+		// Users may be created outside of migrations, collections of the migrated database not
+		if _, exists := options["database"]; !exists {
+			return s.requireCollection(options["collection"].(string))
+		}
+
+	case "createAnalyzer":
+		if s.analyzers[operation.Name] {
+			return fmt.Errorf("analyzer '%s' is already created by an earlier operation", operation.Name)
+		}
+		s.analyzers[operation.Name] = true
+
+	case "deleteAnalyzer":
+		if !s.analyzers[operation.Name] {
+			return fmt.Errorf("analyzer '%s' is not created by an earlier operation", operation.Name)
+		}
+		delete(s.analyzers, operation.Name)
+
+	case "createArangoSearchView", "createSearchAliasView":
+		if s.views[operation.Name] {
+			return fmt.Errorf("view '%s' is already created by an earlier operation", operation.Name)
+		}
+		err := s.requireViewCollections(options)
+		if err != nil {
+			return err
+		}
+		s.views[operation.Name] = true
+
+	case "updateViewProperties":
+		err := s.requireView(operation.Name)
+		if err != nil {
+			return err
+		}
+		return s.requireViewCollections(options)
+
+	case "deleteView":
+		err := s.requireView(operation.Name)
+		if err != nil {
+			return err
+		}
+		delete(s.views, operation.Name)
+	}
+
+	return nil
+}
+
+func (s *validationState) requireCollection(name string) error {
+	if _, exists := s.collections[name]; !exists {
+		return fmt.Errorf("collection '%s' is not created by an earlier operation", name)
+	}
+	return nil
+}
+
+func (s *validationState) requireGraph(name string) error {
+	if !s.graphs[name] {
+		return fmt.Errorf("graph '%s' is not created by an earlier operation", name)
+	}
+	return nil
+}
+
+func (s *validationState) requireView(name string) error {
+	if !s.views[name] {
+		return fmt.Errorf("view '%s' is not created by an earlier operation", name)
+	}
+	return nil
+}
+
+// requireViewCollections checks that the collections linked or indexed by a view exist.
+func (s *validationState) requireViewCollections(options map[string]interface{}) error {
+	for _, collection := range viewCollections(options) {
+		err := s.requireCollection(collection)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requireEdgeDefinition checks that the collections of an edge definition exist
+// and that the edge collection is an edge collection.
+func (s *validationState) requireEdgeDefinition(edgeDefinition arangodb.EdgeDefinition) error {
+	err := s.requireCollection(edgeDefinition.Collection)
+	if err != nil {
+		return err
+	}
+	if s.collections[edgeDefinition.Collection] != "edge" {
+		return fmt.Errorf("collection '%s' used in an edge definition is not an edge collection", edgeDefinition.Collection)
+	}
+
+	for _, vertex := range append(edgeDefinition.From, edgeDefinition.To...) {
+		err := s.requireCollection(vertex)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
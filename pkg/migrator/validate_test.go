@@ -0,0 +1,834 @@
+package migrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateFolderExamples tests that the example migrations are valid
+func TestValidateFolderExamples(t *testing.T) {
+	err := ValidateFolder(filepath.Join("..", "..", "examples", "basic", "migrations"))
+	assert.NoError(t, err)
+}
+
+// TestValidateFolder tests option type checks and cross-file references
+func TestValidateFolder(t *testing.T) {
+	tempDir := t.TempDir()
+
+	migrations := map[string]string{
+		"000001_users.json": `{
+			"description": "Create users",
+			"up": [
+				{
+					"type": "createCollection",
+					"name": "users",
+					"options": {
+						"type": "document"
+					}
+				},
+				{
+					"type": "createPersistentIndex",
+					"name": "idx_email",
+					"options": {
+						"collection": "users",
+						"fields": ["email"],
+						"unique": "true"
+					}
+				},
+				{
+					"type": "createPersistentIndex",
+					"name": "idx_name",
+					"options": {
+						"fields": ["name"]
+					}
+				}
+			],
+			"down": [
+				{
+					"type": "dropEverything",
+					"name": "users"
+				}
+			]
+		}`,
+		"000002_graph.json": `{
+			"description": "Create graph",
+			"up": [
+				{
+					"type": "createPersistentIndex",
+					"name": "idx_title",
+					"options": {
+						"collection": "posts",
+						"fields": ["title"]
+					}
+				},
+				{
+					"type": "createCollection",
+					"name": "follows",
+					"options": {
+						"type": "document"
+					}
+				},
+				{
+					"type": "createGraph",
+					"name": "social",
+					"options": {
+						"edgeDefinitions": [
+							{
+								"collection": "follows",
+								"from": ["users"],
+								"to": ["users"]
+							}
+						]
+					}
+				},
+				{
+					"type": "addEdgeDefinition",
+					"name": "missing_graph",
+					"options": {
+						"edgeDefinition": {
+							"collection": "follows",
+							"from": ["users"],
+							"to": ["users"]
+						}
+					}
+				}
+			]
+		}`,
+		"000003_broken.json": `{ "description": "Not JSON", `,
+	}
+
+	for name, content := range migrations {
+		err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644)
+		require.NoError(t, err)
+	}
+
+	err := ValidateFolder(tempDir)
+	require.Error(t, err)
+
+	var validationErrors ValidationErrors
+	require.True(t, errors.As(err, &validationErrors))
+
+	type problem struct {
+		migrationNumber string
+		list            string
+		index           int
+		message         string
+	}
+
+	expected := []problem{
+		{"000001_users", "up", 1, "unique option not a boolean"},
+		{"000001_users", "up", 2, "collection name missing or not a string"},
+		{"000001_users", "down", 0, "unsupported operation type: dropEverything"},
+		{"000002_graph", "up", 0, "collection 'posts' is not created by an earlier operation"},
+		{"000002_graph", "up", 2, "collection 'follows' used in an edge definition is not an edge collection"},
+		{"000002_graph", "up", 3, "graph 'missing_graph' is not created by an earlier operation"},
+		{"000003_broken", "", 0, "failed to parse migration file"},
+	}
+
+	require.Len(t, validationErrors, len(expected), "unexpected validation errors: %v", err)
+	for i, want := range expected {
+		got := validationErrors[i]
+		assert.Equal(t, want.migrationNumber, got.MigrationNumber)
+		assert.Equal(t, want.list, got.List)
+		assert.Equal(t, want.index, got.OperationIndex)
+		assert.Contains(t, got.Message, want.message)
+	}
+}
+
+// TestValidateFolderMissing tests that an unreadable folder is not reported as validation errors
+func TestValidateFolderMissing(t *testing.T) {
+	err := ValidateFolder(filepath.Join(t.TempDir(), "does_not_exist"))
+	require.Error(t, err)
+
+	var validationErrors ValidationErrors
+	assert.False(t, errors.As(err, &validationErrors))
+}
+
+// TestValidateFS tests validating migrations in an fs.FS
+func TestValidateFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/000001_users.yaml": {Data: []byte(`description: Create users
+up:
+  - type: createCollection
+    name: users
+    options:
+      type: document
+`)},
+		"migrations/000002_index.json": {Data: []byte(`{
+			"description": "Index emails",
+			"up": [
+				{
+					"type": "createPersistentIndex",
+					"name": "idx_email",
+					"options": {
+						"collection": "users",
+						"fields": ["email"],
+						"sparse": "yes"
+					}
+				}
+			]
+		}`)},
+	}
+
+	err := ValidateFS(fsys, "migrations")
+	require.Error(t, err)
+
+	var validationErrors ValidationErrors
+	require.True(t, errors.As(err, &validationErrors))
+	require.Len(t, validationErrors, 1)
+	assert.Equal(t, "000002_index", validationErrors[0].MigrationNumber)
+	assert.Contains(t, validationErrors[0].Message, "sparse option not a boolean")
+}
+
+// TestValidateExecuteAQL tests the option checks of the executeAQL operation
+func TestValidateExecuteAQL(t *testing.T) {
+	operation := func(options map[string]interface{}) Operation {
+		return Operation{Type: "executeAQL", Name: "backfill_active", Options: options}
+	}
+
+	err := validateOperation(operation(map[string]interface{}{
+		"query":         "FOR u IN users UPDATE u WITH { active: true } IN users",
+		"bindVars":      map[string]interface{}{},
+		"rollbackQuery": "FOR u IN users UPDATE u WITH { active: null } IN users OPTIONS { keepNull: false }",
+		"batchSize":     float64(1000),
+		"timeout":       float64(60),
+	}))
+	assert.NoError(t, err)
+
+	err = validateOperation(operation(map[string]interface{}{}))
+	assert.ErrorContains(t, err, "query option missing or not a string")
+
+	err = validateOperation(operation(map[string]interface{}{"query": "RETURN @value", "bindVars": []interface{}{1}}))
+	assert.ErrorContains(t, err, "bindVars option not an object")
+
+	err = validateOperation(operation(map[string]interface{}{"query": "RETURN 1", "rollbackQuery": true}))
+	assert.ErrorContains(t, err, "rollbackQuery option not a string")
+
+	err = validateOperation(operation(map[string]interface{}{"query": "RETURN 1", "batchSize": 2.5}))
+	assert.ErrorContains(t, err, "batchSize option not a positive integer")
+
+	err = validateOperation(operation(map[string]interface{}{"query": "RETURN 1", "timeout": "1m"}))
+	assert.ErrorContains(t, err, "timeout option not a positive number of seconds")
+}
+
+// TestValidateFSTransactional tests that transactional migrations only contain document operations
+func TestValidateFSTransactional(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000001_users.json": {Data: []byte(`{
+			"description": "Create users",
+			"up": [
+				{
+					"type": "createCollection",
+					"name": "users",
+					"options": {
+						"type": "document"
+					}
+				}
+			]
+		}`)},
+		"000002_admins.yaml": {Data: []byte(`description: Add admins
+transactional: true
+up:
+  - type: addDocument
+    name: users
+    options:
+      document:
+        _key: admin
+  - type: createPersistentIndex
+    name: idx_role
+    options:
+      collection: users
+      fields: [role]
+down:
+  - type: deleteDocument
+    name: users
+    options:
+      _key: admin
+  - type: deleteCollection
+    name: users
+`)},
+	}
+
+	err := ValidateFS(fsys, ".")
+	require.Error(t, err)
+
+	var validationErrors ValidationErrors
+	require.True(t, errors.As(err, &validationErrors))
+	require.Len(t, validationErrors, 2, "unexpected validation errors: %v", err)
+
+	assert.Equal(t, "up", validationErrors[0].List)
+	assert.Equal(t, 1, validationErrors[0].OperationIndex)
+	assert.Contains(t, validationErrors[0].Message, "can't be used in a transactional migration")
+	assert.Equal(t, "down", validationErrors[1].List)
+	assert.Equal(t, 1, validationErrors[1].OperationIndex)
+	assert.Contains(t, validationErrors[1].Message, "can't be used in a transactional migration")
+}
+
+// TestValidateIndexOptions tests the option checks of the operations that create indexes
+func TestValidateIndexOptions(t *testing.T) {
+	operation := func(operationType string, options map[string]interface{}) Operation {
+		options["collection"] = "users"
+		return Operation{Type: operationType, Name: "idx_test", Options: options}
+	}
+
+	err := validateOperation(operation("createPersistentIndex", map[string]interface{}{
+		"fields":       []interface{}{"email"},
+		"storedValues": []interface{}{"name"},
+		"cacheEnabled": true,
+		"deduplicate":  false,
+		"estimates":    true,
+		"inBackground": true,
+	}))
+	assert.NoError(t, err)
+
+	err = validateOperation(operation("createPersistentIndex", map[string]interface{}{
+		"fields":       []interface{}{"email"},
+		"storedValues": "name",
+	}))
+	assert.ErrorContains(t, err, "storedValues option not a string array")
+
+	err = validateOperation(operation("createGeoIndex", map[string]interface{}{
+		"fields":         []interface{}{"location"},
+		"legacyPolygons": "no",
+	}))
+	assert.ErrorContains(t, err, "legacyPolygons option not a boolean")
+
+	err = validateOperation(operation("createTTLIndex", map[string]interface{}{
+		"fields":      []interface{}{"createdAt"},
+		"expireAfter": float64(3600),
+	}))
+	assert.NoError(t, err)
+
+	err = validateOperation(operation("createTTLIndex", map[string]interface{}{
+		"fields": []interface{}{"createdAt"},
+	}))
+	assert.ErrorContains(t, err, "expireAfter option missing")
+
+	err = validateOperation(operation("createTTLIndex", map[string]interface{}{
+		"fields":      []interface{}{"createdAt", "updatedAt"},
+		"expireAfter": float64(3600),
+	}))
+	assert.ErrorContains(t, err, "exactly one field")
+
+	err = validateOperation(operation("createInvertedIndex", map[string]interface{}{
+		"analyzer": "text_en",
+		"fields": []interface{}{
+			"title",
+			map[string]interface{}{"name": "tags", "analyzer": "identity"},
+		},
+	}))
+	assert.NoError(t, err)
+
+	err = validateOperation(operation("createInvertedIndex", map[string]interface{}{
+		"includeAllFields": true,
+	}))
+	assert.NoError(t, err)
+
+	err = validateOperation(operation("createInvertedIndex", map[string]interface{}{
+		"analyser": "text_en",
+		"fields":   []interface{}{"title"},
+	}))
+	assert.ErrorContains(t, err, "invalid options")
+
+	err = validateOperation(operation("createMDIIndex", map[string]interface{}{
+		"fields":          []interface{}{"x", "y"},
+		"fieldValueTypes": "double",
+		"prefixFields":    []interface{}{"region"},
+	}))
+	assert.NoError(t, err)
+
+	err = validateOperation(operation("createMDIIndex", map[string]interface{}{
+		"fields":       []interface{}{"x", "y"},
+		"prefixFields": "region",
+	}))
+	assert.ErrorContains(t, err, "prefixFields option not a string array")
+
+	err = validateOperation(operation("createVectorIndex", map[string]interface{}{
+		"fields": []interface{}{"embedding"},
+		"params": map[string]interface{}{
+			"metric":    "cosine",
+			"dimension": float64(768),
+			"nLists":    float64(100),
+		},
+	}))
+	assert.NoError(t, err)
+
+	err = validateOperation(operation("createVectorIndex", map[string]interface{}{
+		"fields": []interface{}{"embedding"},
+		"params": map[string]interface{}{
+			"metric": "cosine",
+		},
+	}))
+	assert.ErrorContains(t, err, "params option must set dimension, metric and nLists")
+
+	err = validateOperation(operation("createFulltextIndex", map[string]interface{}{
+		"fields":    []interface{}{"title"},
+		"minLength": float64(3),
+	}))
+	assert.NoError(t, err)
+
+	err = validateOperation(operation("createFulltextIndex", map[string]interface{}{
+		"fields": []interface{}{"title", "body"},
+	}))
+	assert.ErrorContains(t, err, "fields option must contain exactly one field")
+
+	err = validateOperation(operation("createFulltextIndex", map[string]interface{}{
+		"fields":    []interface{}{"title"},
+		"minLength": float64(0),
+	}))
+	assert.ErrorContains(t, err, "minLength option must be at least 1")
+}
+
+// TestValidateCollectionOptions tests the option checks of createCollection, modifyCollection and setCollectionSchema
+func TestValidateCollectionOptions(t *testing.T) {
+	err := validateOperation(Operation{Type: "createCollection", Name: "orders", Options: map[string]interface{}{
+		"type":           "document",
+		"numberOfShards": float64(3),
+		"shardKeys":      []interface{}{"customerId"},
+		"keyOptions":     map[string]interface{}{"type": "uuid"},
+		"waitForSync":    true,
+		"cacheEnabled":   true,
+	}})
+	assert.NoError(t, err)
+
+	// Unknown options were always ignored, so they are only reported
+	options := map[string]interface{}{
+		"type":          "document",
+		"numberOfShard": float64(3),
+	}
+	err = validateOperation(Operation{Type: "createCollection", Name: "orders", Options: options})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"numberOfShard"}, unknownOptions(options, &arangodb.CreateCollectionProperties{}, "type"))
+
+	err = validateOperation(Operation{Type: "createCollection", Name: "orders", Options: map[string]interface{}{
+		"type":        "document",
+		"waitForSync": "yes",
+	}})
+	assert.ErrorContains(t, err, "invalid options")
+
+	err = validateOperation(Operation{Type: "modifyCollection", Name: "orders", Options: map[string]interface{}{
+		"waitForSync":  false,
+		"cacheEnabled": true,
+	}})
+	assert.NoError(t, err)
+
+	err = validateOperation(Operation{Type: "modifyCollection", Name: "orders"})
+	assert.ErrorContains(t, err, "no collection properties to modify")
+
+	// Immutable properties can't be modified
+	err = validateOperation(Operation{Type: "modifyCollection", Name: "orders", Options: map[string]interface{}{
+		"numberOfShards": float64(6),
+	}})
+	assert.ErrorContains(t, err, "invalid options")
+
+	rule := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"customerId"},
+	}
+
+	err = validateOperation(Operation{Type: "setCollectionSchema", Name: "orders", Options: map[string]interface{}{
+		"rule":    rule,
+		"level":   "moderate",
+		"message": "orders need a customer",
+	}})
+	assert.NoError(t, err)
+
+	schema, err := collectionSchema(map[string]interface{}{"rule": rule})
+	require.NoError(t, err)
+	assert.Equal(t, "strict", schema["level"])
+
+	err = validateOperation(Operation{Type: "setCollectionSchema", Name: "orders", Options: map[string]interface{}{
+		"level": "strict",
+	}})
+	assert.ErrorContains(t, err, "rule option missing or not an object")
+
+	err = validateOperation(Operation{Type: "setCollectionSchema", Name: "orders", Options: map[string]interface{}{
+		"rule":  rule,
+		"level": "lenient",
+	}})
+	assert.ErrorContains(t, err, "level option must be one of none, new, moderate or strict")
+
+	err = validateOperation(Operation{Type: "setCollectionSchema", Name: "orders", Options: map[string]interface{}{
+		"rule":    rule,
+		"message": float64(1),
+	}})
+	assert.ErrorContains(t, err, "message option not a string")
+}
+
+// TestValidateSearchOptions tests the option checks and references of analyzer and view operations
+func TestValidateSearchOptions(t *testing.T) {
+	err := validateOperation(Operation{Type: "createAnalyzer", Name: "text_en_no_stem", Options: map[string]interface{}{
+		"type":       "text",
+		"properties": map[string]interface{}{"locale": "en", "stemming": false},
+		"features":   []interface{}{"frequency", "norm", "position"},
+	}})
+	assert.NoError(t, err)
+
+	err = validateOperation(Operation{Type: "createAnalyzer", Name: "text_en_no_stem", Options: map[string]interface{}{
+		"properties": map[string]interface{}{"locale": "en"},
+	}})
+	assert.ErrorContains(t, err, "analyzer type missing or not a string")
+
+	err = validateOperation(Operation{Type: "deleteAnalyzer", Name: "text_en_no_stem", Options: map[string]interface{}{
+		"force": "yes",
+	}})
+	assert.ErrorContains(t, err, "force option not a boolean")
+
+	err = validateOperation(Operation{Type: "createArangoSearchView", Name: "posts_view", Options: map[string]interface{}{
+		"links": map[string]interface{}{
+			"posts": map[string]interface{}{"analyzers": []interface{}{"text_en"}, "includeAllFields": true},
+		},
+	}})
+	assert.NoError(t, err)
+
+	err = validateOperation(Operation{Type: "createArangoSearchView", Name: "posts_view", Options: map[string]interface{}{
+		"link": map[string]interface{}{},
+	}})
+	assert.ErrorContains(t, err, "invalid options")
+
+	err = validateOperation(Operation{Type: "createSearchAliasView", Name: "posts_search", Options: map[string]interface{}{
+		"indexes": "idx_posts_search",
+	}})
+	assert.ErrorContains(t, err, "invalid options")
+
+	err = validateOperation(Operation{Type: "updateViewProperties", Name: "posts_view"})
+	assert.ErrorContains(t, err, "no view properties to update")
+
+	err = validateOperation(Operation{Type: "updateViewProperties", Name: "posts_view", Options: map[string]interface{}{
+		"indexes": []interface{}{map[string]interface{}{"collection": "posts", "index": "idx_posts_search"}},
+	}})
+	assert.NoError(t, err)
+
+	err = validateOperation(Operation{Type: "updateViewProperties", Name: "posts_view", Options: map[string]interface{}{
+		"link": map[string]interface{}{},
+	}})
+	assert.ErrorContains(t, err, "invalid options")
+
+	// Views can only link collections and be changed after they are created
+	state := newValidationState()
+	err = state.apply(Operation{Type: "createArangoSearchView", Name: "posts_view", Options: map[string]interface{}{
+		"links": map[string]interface{}{"posts": map[string]interface{}{}},
+	}})
+	assert.ErrorContains(t, err, "collection 'posts' is not created by an earlier operation")
+
+	err = state.apply(Operation{Type: "createCollection", Name: "posts", Options: map[string]interface{}{"type": "document"}})
+	require.NoError(t, err)
+	err = state.apply(Operation{Type: "createArangoSearchView", Name: "posts_view", Options: map[string]interface{}{
+		"links": map[string]interface{}{"posts": map[string]interface{}{}},
+	}})
+	assert.NoError(t, err)
+
+	err = state.apply(Operation{Type: "updateViewProperties", Name: "comments_view", Options: map[string]interface{}{
+		"cleanupIntervalStep": float64(4),
+	}})
+	assert.ErrorContains(t, err, "view 'comments_view' is not created by an earlier operation")
+
+	// Removing a link doesn't require the collection to exist
+	err = state.apply(Operation{Type: "updateViewProperties", Name: "posts_view", Options: map[string]interface{}{
+		"links": map[string]interface{}{"comments": nil},
+	}})
+	assert.NoError(t, err)
+
+	err = state.apply(Operation{Type: "deleteView", Name: "posts_view"})
+	assert.NoError(t, err)
+	err = state.apply(Operation{Type: "deleteView", Name: "posts_view"})
+	assert.ErrorContains(t, err, "view 'posts_view' is not created by an earlier operation")
+
+	err = state.apply(Operation{Type: "deleteAnalyzer", Name: "text_en_no_stem"})
+	assert.ErrorContains(t, err, "analyzer 'text_en_no_stem' is not created by an earlier operation")
+}
+
+// TestValidateRenameTruncate tests the option checks and references of renameCollection and truncateCollection
+func TestValidateRenameTruncate(t *testing.T) {
+	err := validateOperation(Operation{Type: "renameCollection", Name: "users_v1", Options: map[string]interface{}{
+		"newName": "users",
+	}})
+	assert.NoError(t, err)
+
+	err = validateOperation(Operation{Type: "renameCollection", Name: "users_v1"})
+	assert.ErrorContains(t, err, "newName option missing or not a string")
+
+	err = validateOperation(Operation{Type: "truncateCollection", Name: "sessions"})
+	assert.NoError(t, err)
+
+	err = validateOperation(Operation{Type: "truncateCollection", Name: "sessions", Options: map[string]interface{}{
+		"backup": "snapshot",
+	}})
+	assert.ErrorContains(t, err, "backup option must be one of none, rollbackData or collection")
+
+	err = validateOperation(Operation{Type: "truncateCollection", Name: "sessions", Options: map[string]interface{}{
+		"backup":           "rollbackData",
+		"backupCollection": "sessions_old",
+	}})
+	assert.ErrorContains(t, err, "backupCollection option requires backup to be collection")
+
+	backup, backupCollection, err := truncateBackup("sessions", map[string]interface{}{"backup": "collection"})
+	require.NoError(t, err)
+	assert.Equal(t, "collection", backup)
+	assert.Equal(t, "sessions_backup", backupCollection)
+
+	// A renamed collection keeps its indexes under the new name
+	state := newValidationState()
+	err = state.apply(Operation{Type: "createCollection", Name: "users_v1", Options: map[string]interface{}{"type": "document"}})
+	require.NoError(t, err)
+	err = state.apply(Operation{Type: "createPersistentIndex", Name: "idx_email", Options: map[string]interface{}{"collection": "users_v1"}})
+	require.NoError(t, err)
+
+	err = state.apply(Operation{Type: "renameCollection", Name: "users_v1", Options: map[string]interface{}{"newName": "users"}})
+	assert.NoError(t, err)
+	err = state.apply(Operation{Type: "deleteIndex", Name: "idx_email", Options: map[string]interface{}{"collection": "users"}})
+	assert.NoError(t, err)
+	err = state.apply(Operation{Type: "addDocument", Name: "users_v1", Options: map[string]interface{}{"document": map[string]interface{}{}}})
+	assert.ErrorContains(t, err, "collection 'users_v1' is not created by an earlier operation")
+
+	// The backup collection is created by the truncation
+	err = state.apply(Operation{Type: "truncateCollection", Name: "users", Options: map[string]interface{}{"backup": "collection"}})
+	assert.NoError(t, err)
+	err = state.apply(Operation{Type: "deleteCollection", Name: "users_backup"})
+	assert.NoError(t, err)
+}
+
+// TestValidateGraphOptions tests the option checks and references of the graph operations
+func TestValidateGraphOptions(t *testing.T) {
+	edgeDefinitions := []interface{}{
+		map[string]interface{}{"collection": "follows", "from": []interface{}{"users"}, "to": []interface{}{"users"}},
+	}
+
+	definition, createOptions, err := graphDefinition(map[string]interface{}{
+		"edgeDefinitions":     edgeDefinitions,
+		"orphanCollections":   []interface{}{"countries"},
+		"isSmart":             true,
+		"smartGraphAttribute": "region",
+		"isDisjoint":          true,
+		"numberOfShards":      float64(9),
+		"replicationFactor":   float64(2),
+		"satellites":          []interface{}{"countries"},
+	})
+	require.NoError(t, err)
+	assert.True(t, definition.IsSmart)
+	assert.True(t, definition.IsDisjoint)
+	assert.Equal(t, "region", definition.SmartGraphAttribute)
+	require.NotNil(t, definition.NumberOfShards)
+	assert.Equal(t, 9, *definition.NumberOfShards)
+	assert.Len(t, definition.EdgeDefinitions, 1)
+	assert.Equal(t, []string{"countries"}, definition.OrphanCollections)
+	assert.Equal(t, []string{"countries"}, createOptions.Satellites)
+
+	// Unknown options were always ignored, so they are only reported
+	options := map[string]interface{}{
+		"edgeDefinitions": edgeDefinitions,
+		"isSmarty":        true,
+	}
+	err = validateOperation(Operation{Type: "createGraph", Name: "social", Options: options})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"isSmarty"}, unknownOptions(options, &arangodb.GraphDefinition{}, graphDefinitionOptions...))
+
+	err = validateOperation(Operation{Type: "createGraph", Name: "social", Options: map[string]interface{}{
+		"edgeDefinitions": edgeDefinitions,
+		"satellites":      "countries",
+	}})
+	assert.ErrorContains(t, err, "satellites option not a string array")
+
+	err = validateOperation(Operation{Type: "replaceEdgeDefinition", Name: "social", Options: map[string]interface{}{
+		"collection": "follows",
+		"from":       []interface{}{"users"},
+	}})
+	assert.ErrorContains(t, err, "to option missing or not a non-empty string array")
+
+	err = validateOperation(Operation{Type: "addVertexCollection", Name: "social"})
+	assert.ErrorContains(t, err, "collection option missing or not a string")
+
+	state := newValidationState()
+	for _, operation := range []Operation{
+		{Type: "createCollection", Name: "users", Options: map[string]interface{}{"type": "document"}},
+		{Type: "createCollection", Name: "follows", Options: map[string]interface{}{"type": "edge"}},
+		{Type: "createGraph", Name: "social", Options: map[string]interface{}{"edgeDefinitions": edgeDefinitions}},
+	} {
+		require.NoError(t, state.apply(operation))
+	}
+
+	err = state.apply(Operation{Type: "replaceEdgeDefinition", Name: "social", Options: map[string]interface{}{
+		"collection": "follows",
+		"from":       []interface{}{"users"},
+		"to":         []interface{}{"organizations"},
+	}})
+	assert.ErrorContains(t, err, "collection 'organizations' is not created by an earlier operation")
+
+	err = state.apply(Operation{Type: "removeVertexCollection", Name: "social", Options: map[string]interface{}{"collection": "organizations"}})
+	assert.ErrorContains(t, err, "collection 'organizations' is not created by an earlier operation")
+
+	// Adding a vertex collection creates it
+	err = state.apply(Operation{Type: "addVertexCollection", Name: "social", Options: map[string]interface{}{"collection": "organizations"}})
+	assert.NoError(t, err)
+	err = state.apply(Operation{Type: "removeVertexCollection", Name: "social", Options: map[string]interface{}{"collection": "organizations"}})
+	assert.NoError(t, err)
+
+	err = state.apply(Operation{Type: "addVertexCollection", Name: "network", Options: map[string]interface{}{"collection": "organizations"}})
+	assert.ErrorContains(t, err, "graph 'network' is not created by an earlier operation")
+}
+
+// TestValidateImportDocuments tests the option checks and import files of the importDocuments operation
+func TestValidateImportDocuments(t *testing.T) {
+	operation := func(options map[string]interface{}) Operation {
+		return Operation{Type: "importDocuments", Name: "countries", Options: options}
+	}
+
+	err := validateOperation(operation(map[string]interface{}{
+		"documents":   []interface{}{map[string]interface{}{"_key": "de"}},
+		"onDuplicate": "replace",
+		"batchSize":   float64(500),
+	}))
+	assert.NoError(t, err)
+
+	err = validateOperation(operation(map[string]interface{}{
+		"documents": []interface{}{map[string]interface{}{"_key": "de"}},
+		"file":      "countries.jsonl",
+	}))
+	assert.ErrorContains(t, err, "exactly one of the documents and file options must be set")
+
+	err = validateOperation(operation(map[string]interface{}{"documents": []interface{}{"de"}}))
+	assert.ErrorContains(t, err, "document 0 is not an object")
+
+	err = validateOperation(operation(map[string]interface{}{"file": "countries.xml"}))
+	assert.ErrorContains(t, err, "file option must be a .jsonl, .ndjson or .csv file")
+
+	err = validateOperation(operation(map[string]interface{}{"file": "countries.csv", "onDuplicate": "overwrite"}))
+	assert.ErrorContains(t, err, "onDuplicate option must be one of error, update, replace or ignore")
+
+	fsys := fstest.MapFS{
+		"data/countries.jsonl": {Data: []byte("{\"_key\": \"de\", \"population\": 84}\n\n{\"_key\": \"fr\", \"population\": 68}\n")},
+		"data/countries.csv":   {Data: []byte("_key,name\nde,Germany\nfr,France\n")},
+		"data/broken.jsonl":    {Data: []byte("{\"_key\": \"de\"}\n[1, 2]\n")},
+	}
+
+	documents, err := readImportFile(fsys, "data/countries.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"_key": "de", "population": float64(84)},
+		{"_key": "fr", "population": float64(68)},
+	}, documents)
+
+	documents, err = readImportFile(fsys, "data/countries.csv")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"_key": "de", "name": "Germany"},
+		{"_key": "fr", "name": "France"},
+	}, documents)
+
+	_, err = readImportFile(fsys, "data/broken.jsonl")
+	assert.ErrorContains(t, err, "line 2 is not a JSON object")
+
+	// Import files are read relative to the migration folder and aren't migration files
+	fsys["migrations/000001_countries.yaml"] = &fstest.MapFile{Data: []byte(`description: Import countries
+up:
+  - type: createCollection
+    name: countries
+    options:
+      type: document
+  - type: importDocuments
+    name: countries
+    options:
+      file: data/countries.csv
+  - type: importDocuments
+    name: countries
+    options:
+      file: data/cities.csv
+`)}
+	fsys["migrations/data/countries.csv"] = fsys["data/countries.csv"]
+
+	err = ValidateFS(fsys, "migrations")
+	require.Error(t, err)
+
+	var validationErrors ValidationErrors
+	require.ErrorAs(t, err, &validationErrors)
+	require.Len(t, validationErrors, 1)
+	assert.Equal(t, 2, validationErrors[0].OperationIndex)
+	assert.Contains(t, validationErrors[0].Message, "failed to read import file")
+}
+
+// TestValidateUserOptions tests the option checks of the database, user and access operations
+func TestValidateUserOptions(t *testing.T) {
+	t.Setenv("ORDERS_SERVICE_PASSWORD", "secret")
+
+	userOptions, err := userOptions(map[string]interface{}{
+		"passwordEnv": "ORDERS_SERVICE_PASSWORD",
+		"active":      true,
+		"extra":       map[string]interface{}{"team": "orders"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "secret", userOptions.Password)
+	require.NotNil(t, userOptions.Active)
+	assert.True(t, *userOptions.Active)
+
+	err = validateOperation(Operation{Type: "createUser", Name: "orders-service", Options: map[string]interface{}{
+		"password":    "secret",
+		"passwordEnv": "ORDERS_SERVICE_PASSWORD",
+	}})
+	assert.ErrorContains(t, err, "only one of the password and passwordEnv options can be set")
+
+	err = validateOperation(Operation{Type: "createUser", Name: "orders-service", Options: map[string]interface{}{
+		"active": "yes",
+	}})
+	assert.ErrorContains(t, err, "active option not a boolean")
+
+	err = validateOperation(Operation{Type: "grantDatabaseAccess", Name: "orders-service", Options: map[string]interface{}{
+		"grant": "ro",
+	}})
+	assert.NoError(t, err)
+
+	err = validateOperation(Operation{Type: "grantDatabaseAccess", Name: "orders-service", Options: map[string]interface{}{
+		"grant": "admin",
+	}})
+	assert.ErrorContains(t, err, "grant option must be one of rw, ro or none")
+
+	err = validateOperation(Operation{Type: "grantCollectionAccess", Name: "orders-service", Options: map[string]interface{}{
+		"grant": "rw",
+	}})
+	assert.ErrorContains(t, err, "collection option missing or not a string")
+
+	err = validateOperation(Operation{Type: "revokeAccess", Name: "orders-service", Options: map[string]interface{}{
+		"database": "analytics",
+	}})
+	assert.NoError(t, err)
+
+	// Collection access in the migrated database needs the collection
+	state := newValidationState()
+	err = state.apply(Operation{Type: "grantCollectionAccess", Name: "orders-service", Options: map[string]interface{}{
+		"collection": "orders",
+		"grant":      "rw",
+	}})
+	assert.ErrorContains(t, err, "collection 'orders' is not created by an earlier operation")
+
+	err = state.apply(Operation{Type: "createUser", Name: "orders-service"})
+	assert.NoError(t, err)
+	err = state.apply(Operation{Type: "createUser", Name: "orders-service"})
+	assert.ErrorContains(t, err, "user 'orders-service' is already created by an earlier operation")
+
+	// Databases are created with the defaults of their collections
+	databaseOptions, err := databaseOptions(map[string]interface{}{
+		"replicationFactor": 3.0,
+		"writeConcern":      2.0,
+		"sharding":          "single",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, arangodb.ReplicationFactor(3), databaseOptions.Options.ReplicationFactor)
+	assert.Equal(t, 2, databaseOptions.Options.WriteConcern)
+	assert.Equal(t, arangodb.DatabaseShardingSingle, databaseOptions.Options.Sharding)
+
+	err = validateOperation(Operation{Type: "createDatabase", Name: "analytics", Options: map[string]interface{}{
+		"users": []interface{}{map[string]interface{}{"user": "root"}},
+	}})
+	assert.ErrorContains(t, err, "invalid options")
+
+	err = state.apply(Operation{Type: "createDatabase", Name: "analytics"})
+	assert.NoError(t, err)
+	err = state.apply(Operation{Type: "createDatabase", Name: "analytics"})
+	assert.ErrorContains(t, err, "database 'analytics' is already created by an earlier operation")
+}